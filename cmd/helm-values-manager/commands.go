@@ -0,0 +1,492 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"github.com/xunholy/helm-values-manager/pkg/config"
+	"github.com/xunholy/helm-values-manager/pkg/output"
+)
+
+// programName is the name of the binary, used in help text and completion scripts
+const programName = "helm-values-manager"
+
+// command is a subcommand of the CLI with its own flags
+type command struct {
+	Name string
+	// Args describes the positional arguments in the usage line
+	Args    string
+	Summary string
+	// Flags registers the flags of the command on its flag set
+	Flags func(fs flagSet)
+	// Run executes the command once its flags are parsed and configured
+	Run func(fs *flag.FlagSet)
+	// NoConfig skips the config file and environment, for commands that do not analyze values
+	NoConfig bool
+}
+
+// commands lists the subcommands in the order they are shown in the help
+var commands []*command
+
+// activeFlags is the flag set of the running command, used to print its usage on errors
+var activeFlags *flag.FlagSet
+
+func init() {
+	commands = []*command{
+		{
+			Name:    "analyze",
+			Summary: "Analyze downstream values against the upstream chart and write the reports (default)",
+			Flags: func(fs flagSet) {
+				addSourceFlags(fs)
+				addFilterFlags(fs)
+				addOutputFlags(fs)
+				addPolicyFlags(fs)
+				addModeFlags(fs)
+			},
+			Run: func(fs *flag.FlagSet) {
+				runAnalysis()
+			},
+		},
+		{
+			Name:    "optimize",
+			Summary: "Print the downstream values without redundant and unsupported entries",
+			Flags: func(fs flagSet) {
+				addSourceFlags(fs)
+				addFilterFlags(fs)
+			},
+			Run: func(fs *flag.FlagSet) {
+				runAnalysis()
+			},
+		},
+		{
+			Name:    "check",
+			Summary: "Check downstream values against the policies, exit with 3 on violations",
+			Flags: func(fs flagSet) {
+				addSourceFlags(fs)
+				addFilterFlags(fs)
+				addPolicyFlags(fs)
+				fs.BoolVar(&writeBaseline, "write-baseline", false, "record the current findings in the -baseline file instead of checking them")
+			},
+			Run: func(fs *flag.FlagSet) {
+				runAnalysis()
+			},
+		},
+		{
+			Name:    "diff",
+			Summary: "Print the optimization of the downstream values as a patch",
+			Flags: func(fs flagSet) {
+				addSourceFlags(fs)
+				addFilterFlags(fs)
+				fs.StringVar(&diffFormat, "format", "unified", "patch format, one of (unified,json-patch,merge-patch)")
+			},
+			Run: func(fs *flag.FlagSet) {
+				switch diffFormat {
+				case "unified", "json-patch", "merge-patch":
+				default:
+					log.Error().Msgf("unknown -format %q", diffFormat)
+					exitUsage()
+				}
+				runAnalysis()
+			},
+		},
+		{
+			Name:    "explain",
+			Args:    "<path>",
+			Summary: "Show the upstream default and documentation of a key, and how the downstream values set it",
+			Flags: func(fs flagSet) {
+				addSourceFlags(fs)
+				fs.StringVar(&outputFormat, "output", "text", "output format, one of (text,json,yaml)")
+			},
+			Run: func(fs *flag.FlagSet) {
+				if fs.NArg() != 1 || explainKey == "" {
+					exitUsage()
+				}
+				runExplain()
+			},
+		},
+		{
+			Name:    "docs",
+			Summary: "Generate a Markdown table documenting the upstream values",
+			Flags: func(fs flagSet) {
+				fs.StringVar(&upstreamValuesFile, "upstream", "", "path to the values.yaml file to document, or - to read it from stdin")
+				fs.StringVar(&chartName, "chart", "", "name of the Helm chart whose values are documented")
+				fs.StringVar(&chartVersion, "chart-version", "", "specific version of the Helm chart")
+				fs.StringVar(&docsTemplate, "template", "", "path to a Go text/template file replacing the default Markdown table")
+				fs.StringVar(&readmeFile, "readme", "", "path to a README whose section between the "+output.DocsStartMarker+" and "+output.DocsEndMarker+" markers is replaced")
+				fs.BoolVar(&check, "check", false, "exit with 3 when the -readme documentation is out of date instead of updating it")
+			},
+			Run: func(fs *flag.FlagSet) {
+				if fs.NArg() != 0 || (check && readmeFile == "") {
+					exitUsage()
+				}
+				runDocs()
+			},
+		},
+		{
+			Name:    "schema",
+			Summary: "Print a JSON Schema for values.schema.json inferred from the upstream values",
+			Flags: func(fs flagSet) {
+				fs.StringVar(&upstreamValuesFile, "upstream", "", "path to the values.yaml file to describe, or - to read it from stdin")
+				fs.StringVar(&chartName, "chart", "", "name of the Helm chart whose values are described")
+				fs.StringVar(&chartVersion, "chart-version", "", "specific version of the Helm chart")
+			},
+			Run: func(fs *flag.FlagSet) {
+				if fs.NArg() != 0 {
+					exitUsage()
+				}
+				runSchema()
+			},
+		},
+		{
+			Name:    "fmt",
+			Summary: "Format the downstream values, ordering keys like the upstream values",
+			Flags: func(fs flagSet) {
+				addSourceFlags(fs)
+				fs.BoolVar(&writeInPlace, "write", false, "rewrite the -downstream file instead of printing the formatted values")
+				fs.BoolVar(&check, "check", false, "exit with 3 when the -downstream file is not formatted, without changing it")
+				fs.BoolVar(&mergeDuplicates, "merge-duplicates", false, "merge keys defined more than once instead of failing, later definitions take precedence")
+			},
+			Run: func(fs *flag.FlagSet) {
+				if fs.NArg() != 0 || (writeInPlace && check) {
+					exitUsage()
+				}
+				runFormat()
+			},
+		},
+		{
+			Name:    "drift",
+			Summary: "Compare the user supplied values of a release across kube contexts",
+			Flags: func(fs flagSet) {
+				addReleaseFlags(fs)
+				fs.StringVar(&outputFormat, "output", "yaml", "report format, one of (yaml,json,markdown)")
+				fs.StringVar(&outDir, "outdir", "values-analysis", "directory to store output files")
+			},
+			Run: func(fs *flag.FlagSet) {
+				if repo == "" || len(kubeContexts) < 2 {
+					log.Error().Msg("drift requires -repo and at least two -kube-context flags")
+					exitUsage()
+				}
+				runAnalysis()
+			},
+		},
+		{
+			Name:    "upgrade",
+			Summary: "Report how upgrading the chart changes the defaults the downstream values rely on",
+			Flags: func(fs flagSet) {
+				addSourceFlags(fs)
+				fs.StringVar(&targetValuesFile, "target-upstream", "", "path to the values.yaml file of the version to upgrade to")
+				fs.StringVar(&targetVersion, "target-version", "", "version of the -chart to upgrade to")
+				fs.StringVar(&outputFormat, "output", "text", "output format, one of (text,json,yaml)")
+				fs.BoolVar(&check, "check", false, "exit with 3 when the upgrade removes a downstream key or changes its type")
+			},
+			Run: func(fs *flag.FlagSet) {
+				if fs.NArg() != 0 {
+					exitUsage()
+				}
+				runUpgrade()
+			},
+		},
+		{
+			Name:     "completion",
+			Args:     "bash|zsh|fish",
+			Summary:  "Print a shell completion script",
+			NoConfig: true,
+			Flags:    func(fs flagSet) {},
+			Run: func(fs *flag.FlagSet) {
+				if fs.NArg() != 1 {
+					exitUsage()
+				}
+				if err := writeCompletion(os.Stdout, fs.Arg(0)); err != nil {
+					log.Error().Err(err).Msg("invalid shell")
+					exitUsage()
+				}
+			},
+		},
+		{
+			Name:     "help",
+			Args:     "[command]",
+			Summary:  "Show the help of a command",
+			NoConfig: true,
+			Flags:    func(fs flagSet) {},
+			Run: func(fs *flag.FlagSet) {
+				if fs.NArg() == 0 {
+					printUsage(os.Stdout)
+					return
+				}
+				cmd := lookupCommand(fs.Arg(0))
+				if cmd == nil {
+					log.Error().Msgf("unknown command %q", fs.Arg(0))
+					printUsage(os.Stderr)
+					os.Exit(exitInvalidUsage)
+				}
+				cmdFlags := detachedFlagSet(cmd)
+				cmdFlags.SetOutput(os.Stdout)
+				cmdFlags.Usage()
+			},
+		},
+	}
+}
+
+// addSourceFlags registers the flags selecting the upstream and downstream values
+func addSourceFlags(fs flagSet) {
+	addReleaseFlags(fs)
+	fs.StringVar(&chartName, "chart", "", "name of the Helm chart to fetch upstream values from")
+	fs.StringVar(&chartVersion, "chart-version", "", "specific version of the Helm chart")
+	fs.StringVar(&upstreamValuesFile, "upstream", "", "path to the upstream values.yaml file, or - to read it from stdin")
+	fs.StringVar(&downstreamValuesFile, "downstream", "", "path to the downstream values.yaml file, or - to read it from stdin")
+	fs.BoolVar(&releaseChart, "release-chart", false, "use the defaults of the chart embedded in the -repo release as upstream values")
+}
+
+// addReleaseFlags registers the flags locating a Helm release
+func addReleaseFlags(fs flagSet) {
+	fs.StringVar(&repo, "repo", "", "chart repository url where to locate the requested chart")
+	fs.IntVar(&revision, "revision", 0, "specify a revision constraint for the chart revision to use")
+	fs.StringVar(&kubeConfigFile, "kubeconfig", defaultKubeConfigPath, "path to the kubeconfig file")
+	fs.StringListVar(&kubeContexts, "kube-context", "name of the kubeconfig context to use, repeat with -repo to compare the release across contexts")
+	fs.StringVar(&namespace, "namespace", "", "namespace scope for this request")
+}
+
+// addFilterFlags registers the flags hiding findings
+func addFilterFlags(fs flagSet) {
+	fs.StringListVar(&ignoreSpecs, "ignore", "suppress the findings of paths matching a glob, optionally for some categories only (e.g. image.tag or podAnnotations.*=unsupported), can be repeated")
+	fs.StringVar(&baselineFile, "baseline", "", "path to a baseline file, findings recorded in it are not reported and do not fail the check")
+	fs.BoolVar(&strictEmptyMaps, "strict-empty-maps", false, "analyze keys set under empty upstream maps (e.g. podAnnotations: {}) instead of leaving them free-form")
+}
+
+// addOutputFlags registers the flags selecting the reports
+func addOutputFlags(fs flagSet) {
+	fs.StringVar(&outputFormat, "output", "stdout", "comma-separated output formats. Any of: ("+strings.Join(output.WriterNames(), ",")+"), or one of (yaml,json,markdown) with -history or several -kube-context")
+	fs.StringVar(&outDir, "outdir", "values-analysis", "directory to store output files")
+	fs.BoolVar(&optimize, "optimize", false, "optimize values.yaml by removing redundant values")
+	fs.StringVar(&templateFile, "template", "", "path to a Go text/template file rendered to stdout with the analysis results (implies -output template)")
+	fs.BoolVar(&writeBaseline, "write-baseline", false, "record the current findings in the -baseline file instead of reporting them")
+}
+
+// addPolicyFlags registers the policies evaluated by checks
+func addPolicyFlags(fs flagSet) {
+	fs.BoolVar(&failOnUnsupported, "fail-on-unsupported", true, "fail the check when any unsupported value is found")
+	fs.BoolVar(&failOnTypeMismatch, "fail-on-type-mismatch", true, "fail the check when any value has a different type than the upstream default")
+	fs.IntVar(&maxRedundant, "max-redundant", -1, "fail the check when more redundant values are found, -1 disables the limit")
+	fs.StringVar(&schemaFile, "schema", "", "path to a values.schema.json the values are validated against, by default the schema of the -release-chart")
+	fs.BoolVar(&failOnSchemaViolation, "fail-on-schema-violation", true, "fail the check when any value is rejected by the -schema")
+}
+
+// addModeFlags registers the modes of the historical flag-only invocation
+func addModeFlags(fs flagSet) {
+	fs.BoolVar(&check, "check", false, "check values against the policies and exit non-zero on violations, without writing files")
+	fs.BoolVar(&history, "history", false, "report how user supplied values changed across the revisions of the -repo release")
+	fs.BoolVar(&scan, "scan", false, "analyze every release against its embedded chart and summarize the findings")
+	fs.BoolVar(&allNamespaces, "all-namespaces", false, "scan releases across all namespaces")
+	fs.StringVar(&selector, "selector", "", "label selector to filter the scanned releases (e.g. key1=value1,key2=value2)")
+	fs.IntVar(&scanWorkers, "workers", 4, "number of releases analyzed concurrently when scanning")
+}
+
+// flagSet registers the flags of a command, either bound to the package variables or, when detached, to throwaway ones
+type flagSet struct {
+	*flag.FlagSet
+	detached bool
+}
+
+// StringVar defines a string flag
+func (fs flagSet) StringVar(p *string, name, value, usage string) {
+	if fs.detached {
+		p = new(string)
+	}
+	fs.FlagSet.StringVar(p, name, value, usage)
+}
+
+// BoolVar defines a bool flag
+func (fs flagSet) BoolVar(p *bool, name string, value bool, usage string) {
+	if fs.detached {
+		p = new(bool)
+	}
+	fs.FlagSet.BoolVar(p, name, value, usage)
+}
+
+// IntVar defines an int flag
+func (fs flagSet) IntVar(p *int, name string, value int, usage string) {
+	if fs.detached {
+		p = new(int)
+	}
+	fs.FlagSet.IntVar(p, name, value, usage)
+}
+
+// StringListVar defines a flag that can be repeated
+func (fs flagSet) StringListVar(p *stringList, name, usage string) {
+	if fs.detached {
+		p = new(stringList)
+	}
+	fs.FlagSet.Var(p, name, usage)
+}
+
+// newFlagSet creates the flag set of a command, binding its flags to the package variables
+func newFlagSet(cmd *command) *flag.FlagSet {
+	return buildFlagSet(cmd, false)
+}
+
+// detachedFlagSet creates the flag set of a command without touching the package variables, to inspect its flags
+func detachedFlagSet(cmd *command) *flag.FlagSet {
+	return buildFlagSet(cmd, true)
+}
+
+// buildFlagSet creates the flag set of a command with its usage
+func buildFlagSet(cmd *command, detached bool) *flag.FlagSet {
+	fs := flagSet{FlagSet: flag.NewFlagSet(programName+" "+cmd.Name, flag.ContinueOnError), detached: detached}
+	cmd.Flags(fs)
+	if !cmd.NoConfig {
+		fs.StringVar(&configFile, "config", "", "path to the config file, by default "+config.FileName+" is searched from the working directory upwards")
+	}
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fmt.Fprintf(w, "%s\n\nUsage:\n  %s\n", cmd.Summary, strings.TrimSpace(programName+" "+cmd.Name+" [flags] "+cmd.Args))
+		if hasFlags(fs.FlagSet) {
+			fmt.Fprintln(w, "\nFlags:")
+			fs.PrintDefaults()
+		}
+	}
+
+	return fs.FlagSet
+}
+
+// hasFlags reports whether a flag set defines any flag
+func hasFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+// lookupCommand returns the command of the given name, or nil
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// commandFlags returns the sorted flag names of a command
+func commandFlags(cmd *command) []string {
+	var names []string
+	detachedFlagSet(cmd).VisitAll(func(f *flag.Flag) {
+		names = append(names, f.Name)
+	})
+	sort.Strings(names)
+	return names
+}
+
+// knownFlagNames returns the flags of each command, the keys accepted by the config file and its sections
+func knownFlagNames() map[string]map[string]bool {
+	known := make(map[string]map[string]bool)
+	for _, cmd := range commands {
+		if cmd.NoConfig {
+			continue
+		}
+		known[cmd.Name] = make(map[string]bool)
+		for _, name := range commandFlags(cmd) {
+			known[cmd.Name][name] = true
+		}
+	}
+	return known
+}
+
+// printUsage prints the list of commands
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Analyze, optimize, and clean Helm values.yaml files\n\nUsage:\n  %s <command> [flags]\n  %s [flags]  (same as analyze)\n\nCommands:\n", programName, programName)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.Name, cmd.Summary)
+	}
+	fmt.Fprintf(w, "\nRun '%s help <command>' for the flags of a command.\n", programName)
+}
+
+// exitUsage prints the usage of the running command and exits with the usage error code
+func exitUsage() {
+	if activeFlags != nil {
+		activeFlags.Usage()
+	} else {
+		printUsage(os.Stderr)
+	}
+	os.Exit(exitInvalidUsage)
+}
+
+// writeDiff prints the optimization of the downstream values in the format selected with -format
+func writeDiff(w io.Writer, valueStatus analyzer.ValueStatus, downstreamContent []byte) error {
+	var patch []byte
+	var err error
+
+	switch diffFormat {
+	case "unified":
+		var diff string
+		diff, err = output.RenderUnifiedDiff(downstreamContent, filepath.ToSlash(valueStatus.Source), valueStatus.Removed)
+		patch = []byte(diff)
+	case "json-patch":
+		patch, err = output.RenderJSONPatch(valueStatus.Removed)
+		patch = append(patch, '\n')
+	case "merge-patch":
+		patch, err = output.RenderMergePatch(valueStatus.Removed)
+		patch = append(patch, '\n')
+	default:
+		return fmt.Errorf("unknown diff format %q", diffFormat)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(patch)
+	return err
+}
+
+func main() {
+	args := os.Args[1:]
+
+	// Without a command the flags are those of analyze, the historical invocation
+	cmd := lookupCommand("analyze")
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd = lookupCommand(args[0])
+		if cmd == nil {
+			log.Error().Msgf("unknown command %q", args[0])
+			printUsage(os.Stderr)
+			os.Exit(exitInvalidUsage)
+		}
+		args = args[1:]
+	}
+
+	knownFlags := knownFlagNames()
+
+	activeFlags = newFlagSet(cmd)
+	if err := activeFlags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(exitInvalidUsage)
+	}
+
+	// Each command selects the analysis mode matching its purpose
+	switch cmd.Name {
+	case "optimize":
+		outputFormat = "optimized-yaml"
+		optimize = true
+	case "check":
+		check = true
+	case "diff":
+		printDiff = true
+	case "explain":
+		explainKey = activeFlags.Arg(0)
+		quietLogs = true
+	case "docs", "schema", "fmt", "upgrade":
+		quietLogs = true
+	}
+
+	if !cmd.NoConfig {
+		setup(cmd, activeFlags, knownFlags)
+	}
+	cmd.Run(activeFlags)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeCompletion prints the completion script of a shell, generated from the commands and their flags
+func writeCompletion(w io.Writer, shell string) error {
+	switch shell {
+	case "bash":
+		writeBashCompletion(w)
+	case "zsh":
+		// zsh runs the bash completion through its compatibility layer
+		fmt.Fprintln(w, "autoload -U +X bashcompinit && bashcompinit")
+		writeBashCompletion(w)
+	case "fish":
+		writeFishCompletion(w)
+	default:
+		return fmt.Errorf("unsupported shell %q, expected one of (bash,zsh,fish)", shell)
+	}
+	return nil
+}
+
+// writeBashCompletion prints a bash completion function completing commands, flags and file names
+func writeBashCompletion(w io.Writer) {
+	function := "_" + strings.ReplaceAll(programName, "-", "_")
+	names := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		names = append(names, cmd.Name)
+	}
+
+	fmt.Fprintf(w, "%s() {\n", function)
+	fmt.Fprintln(w, `    local cur="${COMP_WORDS[COMP_CWORD]}" command="analyze" flags=""`)
+	fmt.Fprintln(w, `    if [[ ${COMP_CWORD} -gt 1 && ${COMP_WORDS[1]} != -* ]]; then command="${COMP_WORDS[1]}"; fi`)
+	fmt.Fprintf(w, "    if [[ ${COMP_CWORD} -eq 1 && ${cur} != -* ]]; then\n")
+	fmt.Fprintf(w, "        COMPREPLY=($(compgen -W %q -- \"${cur}\"))\n", strings.Join(names, " "))
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, `    case "${command}" in`)
+	for _, cmd := range commands {
+		flags := commandFlags(cmd)
+		for i, name := range flags {
+			flags[i] = "-" + name
+		}
+		fmt.Fprintf(w, "        %s) flags=%q ;;\n", cmd.Name, strings.Join(flags, " "))
+	}
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, `    if [[ ${cur} == -* ]]; then`)
+	fmt.Fprintln(w, `        COMPREPLY=($(compgen -W "${flags}" -- "${cur}"))`)
+	fmt.Fprintln(w, "    else")
+	fmt.Fprintln(w, `        COMPREPLY=($(compgen -f -- "${cur}"))`)
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintf(w, "complete -o filenames -F %s %s\n", function, programName)
+}
+
+// writeFishCompletion prints fish completions for the commands and their flags
+func writeFishCompletion(w io.Writer) {
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -f -a %s -d %q\n", programName, cmd.Name, cmd.Summary)
+	}
+
+	for _, cmd := range commands {
+		detachedFlagSet(cmd).VisitAll(func(f *flag.Flag) {
+			fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from %s' -o %s -d %q\n", programName, cmd.Name, f.Name, firstLine(f.Usage))
+		})
+	}
+}
+
+// firstLine shortens a flag usage to its first clause for completion descriptions
+func firstLine(usage string) string {
+	if i := strings.IndexAny(usage, ",("); i > 0 {
+		return strings.TrimSpace(usage[:i])
+	}
+	return usage
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"github.com/xunholy/helm-values-manager/pkg/output"
+	"github.com/xunholy/helm-values-manager/pkg/util"
+)
+
+// runDocs documents the upstream values, printing the table or updating the -readme file
+func runDocs() {
+	if upstreamValuesFile == "" && chartName == "" {
+		log.Error().Msg("docs requires -upstream or -chart")
+		exitUsage()
+	}
+
+	upstream := loadUpstream(outDir, false)
+	if upstream.Raw == nil {
+		fatal(fmt.Errorf("%w: the raw values of %s are not available", analyzer.ErrInvalidValues, upstream.Name), "failed to document values")
+	}
+
+	keyDocs, err := analyzer.ParseKeyDocs(upstream.Raw)
+	if err != nil {
+		fatal(err, "failed to parse values")
+	}
+
+	data := output.NewDocsData(keyDocs, output.Metadata{
+		Chart:        chartName,
+		ChartVersion: upstream.ChartVersion,
+		Upstream:     util.DisplayName(upstreamValuesFile),
+	})
+	docs, err := output.RenderDocs(data, docsTemplate)
+	if err != nil {
+		fatal(err, "failed to render documentation")
+	}
+
+	if readmeFile == "" {
+		fmt.Print(docs)
+		return
+	}
+
+	readme, err := os.ReadFile(readmeFile)
+	if err != nil {
+		fatal(err, fmt.Sprintf("failed to read README: %s", readmeFile))
+	}
+	updated, err := output.UpdateReadme(readme, docs)
+	if err != nil {
+		fatal(err, fmt.Sprintf("failed to update README: %s", readmeFile))
+	}
+
+	if check {
+		if !bytes.Equal(readme, updated) {
+			fmt.Printf("FAIL: %s is out of date, run %s docs without -check to update it\n", readmeFile, programName)
+			os.Exit(exitCheckFailed)
+		}
+		fmt.Println("PASS")
+		return
+	}
+
+	if bytes.Equal(readme, updated) {
+		log.Warn().Msgf("%s is up to date", readmeFile)
+		return
+	}
+	if err := util.CreateOutputFile(updated, readmeFile); err != nil {
+		fatal(writeFailed("README", err), fmt.Sprintf("failed to write README: %s", readmeFile))
+	}
+}
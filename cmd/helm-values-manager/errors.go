@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"github.com/xunholy/helm-values-manager/pkg/config"
+	"github.com/xunholy/helm-values-manager/pkg/helm"
+	"github.com/xunholy/helm-values-manager/pkg/output"
+)
+
+// Exit codes of the command, documented in the README
+const (
+	exitFailure            = 1
+	exitInvalidUsage       = 2
+	exitCheckFailed        = 3
+	exitInvalidInput       = 4
+	exitNotFound           = 5
+	exitClusterUnreachable = 6
+	exitOutputFailed       = 7
+)
+
+// exitCode maps an error to the exit code describing its kind
+func exitCode(err error) int {
+	// Output errors wrap the error of the failed write, their kind is checked first
+	switch {
+	case errors.Is(err, output.ErrUnknownFormat):
+		return exitInvalidUsage
+	case errors.Is(err, output.ErrInvalidTemplate), errors.Is(err, output.ErrDocsMarkers):
+		return exitInvalidInput
+	case errors.Is(err, output.ErrWriteFailed):
+		return exitOutputFailed
+	case errors.Is(err, helm.ErrClusterUnreachable):
+		return exitClusterUnreachable
+	case errors.Is(err, helm.ErrReleaseNotFound), errors.Is(err, helm.ErrChartNotFound), errors.Is(err, os.ErrNotExist):
+		return exitNotFound
+	case errors.Is(err, analyzer.ErrInvalidValues), errors.Is(err, analyzer.ErrInvalidIgnoreRule),
+		errors.Is(err, analyzer.ErrInvalidAnnotation), errors.Is(err, analyzer.ErrInvalidBaseline), errors.Is(err, analyzer.ErrDuplicateKeys),
+		errors.Is(err, analyzer.ErrInvalidSchema),
+		errors.Is(err, config.ErrInvalidConfig):
+		return exitInvalidInput
+	default:
+		return exitFailure
+	}
+}
+
+// fatal logs an error and exits with the code matching its kind
+func fatal(err error, msg string) {
+	log.Error().Err(err).Msg(msg)
+	os.Exit(exitCode(err))
+}
+
+// writeFailed marks an error writing a file outside of the output writers as an output failure
+func writeFailed(op string, err error) error {
+	return &output.Error{Op: op, Kind: output.ErrWriteFailed, Err: err}
+}
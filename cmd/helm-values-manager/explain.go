@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"github.com/xunholy/helm-values-manager/pkg/output"
+	"github.com/xunholy/helm-values-manager/pkg/util"
+)
+
+// runExplain prints the upstream default and documentation of the explained key, and its downstream value
+// when -downstream is given
+func runExplain() {
+	upstream := loadUpstream(outDir, false)
+
+	var downstreamContent []byte
+	var sourceName string
+	if downstreamValuesFile != "" {
+		downstreamContent, _, _ = loadDownstream()
+		sourceName = util.RelativeName(downstreamValuesFile, projectRoot)
+	}
+
+	report, err := analyzeValues(upstream, downstreamContent, sourceName, analyzer.WithExplain(explainKey))
+	if err != nil {
+		fatal(err, "failed to explain key")
+	}
+	if err := output.WriteExplanation(os.Stdout, *report.Explanation, upstream.Name, outputFormat); err != nil {
+		fatal(err, "failed to explain key")
+	}
+}
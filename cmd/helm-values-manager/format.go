@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"github.com/xunholy/helm-values-manager/pkg/util"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// runFormat formats the downstream values, printing them, rewriting the file or checking it is formatted
+func runFormat() {
+	if downstreamValuesFile == "" {
+		log.Error().Msg("missing -downstream flag")
+		exitUsage()
+	}
+	if writeInPlace && downstreamValuesFile == util.StdinPath {
+		log.Error().Msg("-write cannot rewrite stdin")
+		exitUsage()
+	}
+
+	// Without upstream values the keys are sorted alphabetically
+	var upstreamContent []byte
+	if upstreamValuesFile != "" || chartName != "" || repo != "" {
+		upstream := loadUpstream(outDir, false)
+		upstreamContent = upstream.Raw
+		if upstreamContent == nil {
+			var err error
+			if upstreamContent, err = yamlv3.Marshal(upstream.Values); err != nil {
+				fatal(err, "failed to marshal upstream values")
+			}
+		}
+	}
+
+	content, err := util.ReadInput(downstreamValuesFile)
+	if err != nil {
+		fatal(err, fmt.Sprintf("failed to read downstream values file: %s", downstreamValuesFile))
+	}
+
+	source := content
+	if mergeDuplicates {
+		if source, err = analyzer.MergeDuplicateKeys(content); err != nil {
+			fatal(err, "failed to merge duplicate keys")
+		}
+	} else {
+		checkDuplicateKeys(content)
+	}
+
+	formatted, err := analyzer.FormatValues(source, upstreamContent)
+	if err != nil {
+		fatal(err, "failed to format downstream values")
+	}
+
+	switch {
+	case check:
+		name := util.DisplayName(downstreamValuesFile)
+		if !bytes.Equal(content, formatted) {
+			fmt.Printf("FAIL: %s is not formatted, run %s fmt -write to format it\n", name, programName)
+			os.Exit(exitCheckFailed)
+		}
+		fmt.Println("PASS")
+	case writeInPlace:
+		if bytes.Equal(content, formatted) {
+			return
+		}
+		if err := util.CreateOutputFile(formatted, downstreamValuesFile); err != nil {
+			fatal(writeFailed("formatted values", err), fmt.Sprintf("failed to write %s", downstreamValuesFile))
+		}
+	default:
+		os.Stdout.Write(formatted)
+	}
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,6 +12,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"github.com/xunholy/helm-values-manager/pkg/config"
 	"github.com/xunholy/helm-values-manager/pkg/helm"
 	"github.com/xunholy/helm-values-manager/pkg/output"
 	"github.com/xunholy/helm-values-manager/pkg/util"
@@ -18,77 +21,310 @@ import (
 
 // Command line flags
 var (
-	repo                 string
-	chartName            string
-	chartVersion         string
-	kubeConfigFile       string
-	context              string
-	namespace            string
-	revision             int
-	outputFormat         string
-	upstreamValuesFile   string
-	downstreamValuesFile string
-	outDir               string
-	optimize             bool
+	repo                  string
+	chartName             string
+	chartVersion          string
+	kubeConfigFile        string
+	kubeContextName       string
+	namespace             string
+	revision              int
+	outputFormat          string
+	upstreamValuesFile    string
+	downstreamValuesFile  string
+	outDir                string
+	optimize              bool
+	releaseChart          bool
+	history               bool
+	scan                  bool
+	allNamespaces         bool
+	selector              string
+	scanWorkers           int
+	kubeContexts          stringList
+	check                 bool
+	failOnUnsupported     bool
+	failOnTypeMismatch    bool
+	failOnSchemaViolation bool
+	schemaFile            string
+	maxRedundant          int
+	templateFile          string
+	configFile            string
+	ignoreSpecs           stringList
+	ignoreRules           []analyzer.IgnoreRule
+	baselineFile          string
+	strictEmptyMaps       bool
+	writeBaseline         bool
+	printDiff             bool
+	diffFormat            string
+	explainKey            string
+	docsTemplate          string
+	readmeFile            string
+	quietLogs             bool
+	writeInPlace          bool
+	mergeDuplicates       bool
+	targetValuesFile      string
+	targetVersion         string
+	projectRoot           string
 )
 
+// stringList is a flag value that can be specified multiple times
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// IsRepeatable marks the flag as accepting a list in the config file and environment
+func (s *stringList) IsRepeatable() bool {
+	return true
+}
+
+// pathFlags are the flags holding paths, resolved relative to the config file that sets them
+var pathFlags = []string{"upstream", "downstream", "outdir", "template", "kubeconfig", "baseline", "readme", "schema"}
+
+// defaultKubeConfigPath is the kubeconfig used when -kubeconfig is not given
+var defaultKubeConfigPath string
+
 func init() {
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339})
 
-	defaultKubeConfigPath, err := util.FindKubeConfig()
+	var err error
+	defaultKubeConfigPath, err = util.FindKubeConfig()
 	if err != nil {
 		log.Warn().AnErr("kubeConfigPath", err).Msg("Unable to determine default kubeconfig path")
 	}
+}
+
+// scopedFlags are the flags whose meaning depends on the command. Top-level config keys and environment variables
+// only set them for analyze, other commands read them from their section of the config file.
+var scopedFlags = map[string]bool{"output": true, "template": true, "check": true}
+
+// loadConfig applies the config file and environment overrides to the flags of a command not set on the
+// command line and returns the path of the config file used, if any
+func loadConfig(cmd *command, fs *flag.FlagSet, knownFlags map[string]map[string]bool) (string, error) {
+	path := configFile
+	if path == "" {
+		path = os.Getenv(config.EnvName("config"))
+	}
+	if path == "" {
+		workingDir, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine working directory: %w", err)
+		}
+		if path, err = config.Find(workingDir); err != nil {
+			return "", err
+		}
+	}
+
+	var cfg *config.Config
+	if path != "" {
+		var err error
+		if cfg, err = config.Load(path, knownFlags, pathFlags...); err != nil {
+			return "", err
+		}
+	}
+
+	scoped := func(name string) bool {
+		return cmd.Name != "analyze" && scopedFlags[name]
+	}
+	lookupEnv := func(key string) (string, bool) {
+		for name := range scopedFlags {
+			if scoped(name) && key == config.EnvName(name) {
+				return "", false
+			}
+		}
+		return os.LookupEnv(key)
+	}
+
+	return path, config.Apply(fs, cfg.For(cmd.Name, scoped), lookupEnv)
+}
+
+// setup applies the configuration of a command and validates the flags shared by every command
+func setup(cmd *command, fs *flag.FlagSet, knownFlags map[string]map[string]bool) {
+	usedConfigFile, err := loadConfig(cmd, fs, knownFlags)
+	if err != nil {
+		fatal(err, "failed to load configuration")
+	}
+
+	// Only the comparison of a release uses several contexts, other modes would silently ignore all but the first
+	if len(kubeContexts) > 1 && (history || scan) {
+		log.Error().Msg("-history and -scan accept a single -kube-context")
+		exitUsage()
+	}
+	if len(kubeContexts) > 0 {
+		kubeContextName = kubeContexts[0]
+	}
+
+	// A template replaces the default output, or is added to the explicitly selected ones
+	if templateFile != "" {
+		if outputFormat == "stdout" {
+			outputFormat = "template"
+		} else if !strings.Contains(","+outputFormat+",", ",template,") {
+			outputFormat += ",template"
+		}
+	}
+
+	// Keep stdout clean for documents written to it
+	if output.UsesStdout(output.ParseFormats(outputFormat)) {
+		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
+	}
+
+	// Diffs are printed to stdout as well
+	if printDiff {
+		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
+	}
+
+	// Commands printing a result only keep warnings of the progress logs, on stderr
+	if check || quietLogs {
+		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}).Level(zerolog.WarnLevel)
+	}
+
+	if usedConfigFile != "" {
+		log.Info().Msgf("Using config file: %s", usedConfigFile)
+	}
 
-	// Command-line flag definitions
-	flag.StringVar(&repo, "repo", "", "chart repository url where to locate the requested chart")
-	flag.StringVar(&chartName, "chart", "", "name of the Helm chart to fetch upstream values from")
-	flag.StringVar(&chartVersion, "chart-version", "", "specific version of the Helm chart")
-	flag.IntVar(&revision, "revision", 0, "specify a revision constraint for the chart revision to use")
-	flag.StringVar(&kubeConfigFile, "kubeconfig", defaultKubeConfigPath, "path to the kubeconfig file")
-	flag.StringVar(&context, "kube-context", "", "name of the kubeconfig context to use")
-	flag.StringVar(&namespace, "namespace", "", "namespace scope for this request")
-	flag.StringVar(&outputFormat, "output", "stdout", "output format. One of: (yaml,stdout)")
-	flag.StringVar(&upstreamValuesFile, "upstream", "", "path to the upstream values.yaml file")
-	flag.StringVar(&downstreamValuesFile, "downstream", "", "path to the downstream values.yaml file")
-	flag.StringVar(&outDir, "outdir", "values-analysis", "directory to store output files")
-	flag.BoolVar(&optimize, "optimize", false, "optimize values.yaml by removing redundant values")
+	// Findings refer to the downstream file relative to the repository, or to the config file outside of one,
+	// so that baselines do not depend on the checkout
+	if workingDir, err := os.Getwd(); err == nil {
+		projectRoot = util.FindRepoRoot(workingDir)
+	}
+	if projectRoot == "" && usedConfigFile != "" {
+		projectRoot = filepath.Dir(usedConfigFile)
+	}
+
+	// Ignore rules are validated before any work is done
+	for _, spec := range ignoreSpecs {
+		rule, err := analyzer.ParseIgnoreRule(spec, "-ignore")
+		if err != nil {
+			fatal(err, "invalid -ignore flag")
+		}
+		ignoreRules = append(ignoreRules, rule)
+	}
+
+	// Policies are evaluated on the analysis of a values file, the other modes would silently skip them
+	if check && (history || scan || (repo != "" && len(kubeContexts) > 1)) {
+		log.Error().Msg("-check cannot be combined with -history, -scan or several -kube-context flags")
+		exitUsage()
+	}
+
+	if writeBaseline && baselineFile == "" {
+		log.Error().Msg("-write-baseline requires -baseline")
+		exitUsage()
+	}
+
+	// Standard input can only be consumed once
+	if upstreamValuesFile == util.StdinPath && downstreamValuesFile == util.StdinPath {
+		log.Error().Msg("only one of -upstream and -downstream can be read from stdin")
+		exitUsage()
+	}
 }
 
-func main() {
-	flag.Parse()
+// runAnalysis runs the analysis selected by the flags, the behaviour of the historical flag-only invocation
+func runAnalysis() {
+	// Nothing is written to disk when checking, recording a baseline or when every output of an analysis goes to stdout.
+	// History, comparison and scan modes always write their reports to the output directory.
+	writeFiles := !check && !writeBaseline && !printDiff && (history || scan || (repo != "" && len(kubeContexts) > 1) ||
+		output.WritesFiles(output.ParseFormats(outputFormat)))
 
 	// Create output directory if it doesn't exist
-	if err := util.EnsureDirectory(outDir); err != nil {
-		log.Fatal().Err(err).Msgf("failed to create output directory: %s", outDir)
+	if writeFiles {
+		if err := util.EnsureDirectory(outDir); err != nil {
+			fatal(writeFailed("output directory", err), fmt.Sprintf("failed to create output directory: %s", outDir))
+		}
+	}
+
+	// History mode only needs a release, no upstream or downstream values
+	if history {
+		processHistory()
+		return
+	}
+
+	// Several contexts compare the same release across clusters
+	if repo != "" && len(kubeContexts) > 1 {
+		processContextComparison()
+		return
+	}
+
+	// Every remaining mode writes its results with the registered output writers
+	if err := output.ValidateFormats(output.ParseFormats(outputFormat)); err != nil {
+		log.Error().Err(err).Msg("invalid -output flag")
+		exitUsage()
+	}
+
+	// Scan mode analyzes every release against its own embedded chart
+	if scan {
+		// Releases are analyzed concurrently, documents printed to stdout would interleave
+		if output.UsesStdout(output.ParseFormats(outputFormat)) {
+			log.Error().Msg("-scan writes one report per release, select output formats writing files")
+			exitUsage()
+		}
+		processScan()
+		return
 	}
 
 	// Configure output paths
 	paths := analyzer.NewPathOptions(outDir)
 
 	// Determine source of upstream values
-	upstreamPath := ""
-	var upstreamValues map[string]interface{}
-	var originalUpstreamYAML []byte
+	upstream := loadUpstream(outDir, writeFiles)
+
+	// We need a downstream values file to compare against
+	if downstreamValuesFile == "" {
+		log.Error().Msg("missing -downstream flag")
+		exitUsage()
+	}
+
+	downstreamContent, _, _ := loadDownstream()
+
+	// Process the values
+	processValues(upstream, paths, downstreamContent)
+}
+
+// upstreamSource holds the upstream values of an analysis and where they were loaded from
+type upstreamSource struct {
+	// Name refers to the upstream values in logs and reports
+	Name string
+	// Path is the file holding the values, saved to the output directory when they were fetched
+	Path string
+	// ChartVersion is the resolved version of the chart the values come from, if any
+	ChartVersion string
+	Values       map[string]interface{}
+	Raw          []byte
+	Positions    analyzer.Positions
+	// Schema is the values.schema.json the downstream values are validated against, if any
+	Schema []byte
+}
+
+// loadUpstream loads the upstream values from the file, chart or release selected by the flags.
+// Fetched values are saved to the output directory when writeFiles is set.
+func loadUpstream(outDir string, writeFiles bool) upstreamSource {
+	var upstream upstreamSource
 	var err error
 
 	// Option 1: Use provided upstream file if specified
 	if upstreamValuesFile != "" {
 		log.Info().Msgf("Using provided upstream values file: %s", upstreamValuesFile)
-		upstreamPath = upstreamValuesFile
+		upstream.Name = util.DisplayName(upstreamValuesFile)
+		upstream.Path = upstreamValuesFile
 
 		// Load upstream values
-		originalUpstreamYAML, err = os.ReadFile(upstreamValuesFile)
+		upstream.Raw, err = util.ReadInput(upstreamValuesFile)
 		if err != nil {
-			log.Fatal().Err(err).Msgf("failed to read upstream values file: %s", upstreamValuesFile)
+			fatal(err, fmt.Sprintf("failed to read upstream values file: %s", upstreamValuesFile))
 		}
 
-		if err := yaml.Unmarshal(originalUpstreamYAML, &upstreamValues); err != nil {
-			log.Fatal().Err(err).Msg("failed to parse upstream values YAML")
+		upstream.Values, upstream.Positions, err = analyzer.ParseValues(upstream.Raw)
+		if err != nil {
+			fatal(err, "failed to parse upstream values YAML")
 		}
 	} else if chartName != "" {
 		// Option 2: Fetch upstream values from a Helm chart
 		log.Info().Msgf("Fetching upstream values from chart: %s", chartName)
+		upstream.Name = chartName + " values.yaml"
 
 		// Special handling for known charts with lots of commented fields
 		if strings.Contains(chartName, "cilium") {
@@ -99,116 +335,347 @@ func main() {
 
 		// First get the raw YAML content to preserve comments
 		var helmErr error
-		originalUpstreamYAML, helmErr = helm.FetchChartValuesRaw(chartName, chartVersion)
+		upstream.Raw, helmErr = helm.FetchChartValuesRaw(chartName, chartVersion)
 		if helmErr != nil {
 			log.Warn().Err(helmErr).Msg("Unable to fetch raw values YAML from Helm chart, comments will not be preserved")
 			// Fallback to the regular method
-			upstreamValues, err = helm.FetchChartValues(chartName, chartVersion)
+			upstream.Values, err = helm.FetchChartValues(chartName, chartVersion)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Unable to fetch values from Helm chart")
+				fatal(err, "Unable to fetch values from Helm chart")
 			}
 		} else {
 			// Parse the YAML content for processing
-			if err := yaml.Unmarshal(originalUpstreamYAML, &upstreamValues); err != nil {
+			upstream.Values, upstream.Positions, err = analyzer.ParseValues(upstream.Raw)
+			if err != nil {
 				log.Warn().Err(err).Msg("Error parsing raw YAML, fallback to regular fetch")
 				// Fallback to the regular method
-				upstreamValues, err = helm.FetchChartValues(chartName, chartVersion)
+				upstream.Values, err = helm.FetchChartValues(chartName, chartVersion)
 				if err != nil {
-					log.Fatal().Err(err).Msg("Unable to fetch values from Helm chart")
+					fatal(err, "Unable to fetch values from Helm chart")
 				}
 				// Clear the original YAML since it couldn't be parsed
-				originalUpstreamYAML = nil
+				upstream.Raw = nil
 			}
 		}
 
+		// The latest version is resolved so that reports name the version actually analyzed
+		upstream.ChartVersion = chartVersion
+		if version, err := helm.FetchChartVersion(chartName, chartVersion); err != nil {
+			log.Warn().Err(err).Msg("Unable to resolve the chart version")
+		} else if version != "" {
+			upstream.ChartVersion = version
+		}
+
 		// Save chart values to file
-		upstreamPath = filepath.Join(outDir, "chart-values.yaml")
+		upstream.Path = filepath.Join(outDir, "chart-values.yaml")
 		// Save the original YAML if available, otherwise marshal from map
 		var contentToSave []byte
-		if originalUpstreamYAML != nil {
-			contentToSave = originalUpstreamYAML
+		if upstream.Raw != nil {
+			contentToSave = upstream.Raw
 		} else {
-			contentToSave, err = yaml.Marshal(upstreamValues)
+			contentToSave, err = yaml.Marshal(upstream.Values)
 			if err != nil {
-				log.Fatal().Err(err).Msg("Failed to marshal chart values")
+				fatal(err, "Failed to marshal chart values")
 			}
 		}
 
-		if err := util.CreateOutputFile(contentToSave, upstreamPath); err != nil {
-			log.Fatal().Err(err).Msg("Failed to write chart values to file")
+		if writeFiles {
+			if err := util.CreateOutputFile(contentToSave, upstream.Path); err != nil {
+				fatal(writeFailed("chart values", err), "Failed to write chart values to file")
+			}
 		}
 	} else if repo != "" {
 		// Option 3: Use Helm release values
 		log.Info().Msgf("Fetching values from Helm release: %s", repo)
-		helmClient, err := helm.NewClient(context, namespace, kubeConfigFile)
+		upstream.Name = "release " + repo
+		helmClient, err := helm.NewClient(kubeContextName, namespace, kubeConfigFile)
 		if err != nil {
-			log.Fatal().Err(err).Msg("fetching helm client")
+			fatal(err, "fetching helm client")
 		}
 
-		upstreamValues, err = helmClient.FetchReleaseValues(repo, revision)
-		if err != nil {
-			log.Fatal().Err(err).Msg("fetching helm repo")
+		if releaseChart {
+			// Use the defaults of the chart version that is actually deployed
+			embedded, err := helmClient.FetchReleaseChart(repo, revision)
+			if err != nil {
+				fatal(err, "fetching chart from helm release")
+			}
+			upstream.Values, upstream.Raw = embedded.Values, helm.RawChartValues(embedded)
+			upstream.Schema = embedded.Schema
+			upstream.ChartVersion = helm.ChartVersion(embedded)
+
+			if upstream.Raw == nil {
+				log.Warn().Msg("Release does not carry the raw chart values.yaml, comment detection will be limited")
+			} else if _, upstream.Positions, err = analyzer.ParseValues(upstream.Raw); err != nil {
+				log.Warn().Err(err).Msg("Unable to determine upstream key positions from the raw chart values")
+			}
+
+			upstream.Path = filepath.Join(outDir, "chart-values.yaml")
+		} else {
+			upstream.Values, err = helmClient.FetchReleaseValues(repo, revision)
+			if err != nil {
+				fatal(err, "fetching helm repo")
+			}
+
+			upstream.Path = filepath.Join(outDir, "upstream-values.yaml")
 		}
 
-		// Save release values to file
-		upstreamPath = filepath.Join(outDir, "upstream-values.yaml")
-		marshaledValues, err := yaml.Marshal(upstreamValues)
-		if err != nil {
-			log.Fatal().Err(err).Msg("error while marshaling upstream values")
+		// Save release values to file, preferring the raw chart values when available
+		contentToSave := upstream.Raw
+		if contentToSave == nil {
+			contentToSave, err = yaml.Marshal(upstream.Values)
+			if err != nil {
+				fatal(err, "error while marshaling upstream values")
+			}
 		}
 
-		if err := util.CreateOutputFile(marshaledValues, upstreamPath); err != nil {
-			log.Fatal().Err(err).Msg("unable to write upstream values file")
+		if writeFiles {
+			if err := util.CreateOutputFile(contentToSave, upstream.Path); err != nil {
+				fatal(writeFailed("upstream values", err), "unable to write upstream values file")
+			}
 		}
 	} else {
 		// If no upstream source is provided, show usage
 		log.Error().Msg("No upstream values source specified. Use one of: -upstream, -chart, or -repo")
-		flag.Usage()
-		os.Exit(2)
+		exitUsage()
 	}
 
-	// We need a downstream values file to compare against
-	if downstreamValuesFile == "" {
-		log.Error().Msg("missing -downstream flag")
-		flag.Usage()
-		os.Exit(2)
+	// An explicit schema replaces the one embedded in the chart
+	if schemaFile != "" {
+		if upstream.Schema, err = os.ReadFile(schemaFile); err != nil {
+			fatal(err, fmt.Sprintf("failed to read schema: %s", schemaFile))
+		}
 	}
 
-	// Load downstream values
-	downstreamContent, err := os.ReadFile(downstreamValuesFile)
+	return upstream
+}
+
+// loadDownstream reads and parses the -downstream values file
+func loadDownstream() ([]byte, map[string]interface{}, analyzer.Positions) {
+	content, err := util.ReadInput(downstreamValuesFile)
 	if err != nil {
-		log.Fatal().Err(err).Msgf("failed to read downstream values file: %s", downstreamValuesFile)
+		fatal(err, fmt.Sprintf("failed to read downstream values file: %s", downstreamValuesFile))
 	}
 
-	var downstreamValues map[string]interface{}
-	if err := yaml.Unmarshal(downstreamContent, &downstreamValues); err != nil {
-		log.Fatal().Err(err).Msg("failed to parse downstream values YAML")
+	values, positions, err := analyzer.ParseValues(content)
+	if err != nil {
+		fatal(err, "failed to parse downstream values YAML")
 	}
 
-	// Process the values
-	processValues(upstreamValues, downstreamValues, paths, originalUpstreamYAML)
+	checkDuplicateKeys(content)
+
+	return content, values, positions
+}
+
+// checkDuplicateKeys fails when the downstream values define a key more than once, yaml.v2 would silently
+// keep the last definition only
+func checkDuplicateKeys(content []byte) {
+	duplicates, err := analyzer.FindDuplicateKeys(content)
+	if err != nil {
+		fatal(err, "failed to parse downstream values YAML")
+	}
+	if len(duplicates) == 0 {
+		return
+	}
+
+	name := util.DisplayName(downstreamValuesFile)
+	for _, duplicate := range duplicates {
+		lines := make([]string, 0, len(duplicate.Lines))
+		for _, line := range duplicate.Lines {
+			lines = append(lines, fmt.Sprint(line))
+		}
+		log.Error().Msgf("duplicate key %s defined in %s on lines %s", duplicate.Path, name, strings.Join(lines, ", "))
+	}
+	fatal(&analyzer.DuplicateKeysError{File: name, Duplicates: duplicates},
+		"only the last definition of a duplicate key is used, merge them with "+programName+" fmt -merge-duplicates")
+}
+
+// analyzeValues analyzes the downstream content against the upstream values with the configured ignore rules.
+// Malformed inline annotations are reported and skipped rather than failing the analysis.
+func analyzeValues(upstream upstreamSource, downstreamContent []byte, sourceName string, opts ...analyzer.Option) (*analyzer.Report, error) {
+	options := []analyzer.Option{
+		analyzer.WithSourceName(sourceName),
+		analyzer.WithIgnoreRules(ignoreRules...),
+		analyzer.WithFreeFormMaps(!strictEmptyMaps),
+	}
+
+	upstreamContent := upstream.Raw
+	if len(upstreamContent) == 0 {
+		// Values fetched without their values.yaml carry neither comments nor positions
+		var err error
+		if upstreamContent, err = yaml.Marshal(upstream.Values); err != nil {
+			return nil, err
+		}
+		options = append(options, analyzer.WithCommentDetection(false), analyzer.WithPositions(false, true))
+	}
+	if upstream.Schema != nil {
+		options = append(options, analyzer.WithSchema(upstream.Schema))
+	}
+
+	if _, err := analyzer.ParseAnnotations(downstreamContent, sourceName); err != nil {
+		log.Warn().Err(err).Msg("ignoring invalid annotations in the downstream values")
+		options = append(options, analyzer.WithInlineAnnotations(false))
+	}
+
+	return analyzer.Analyze(context.Background(), upstreamContent, downstreamContent, append(options, opts...)...)
 }
 
 // processValues analyzes upstream and downstream values and generates reports
-func processValues(upstreamValues, downstreamValues map[string]interface{}, paths analyzer.PathOptions, originalUpstreamYAML []byte) {
+func processValues(upstream upstreamSource, paths analyzer.PathOptions, downstreamContent []byte) {
 	log.Info().Msg("Processing upstream and downstream values")
-
-	// Create analyzer with original YAML for better analysis
-	var valueAnalyzer *analyzer.Analyzer
-	if originalUpstreamYAML != nil && len(originalUpstreamYAML) > 0 {
-		valueAnalyzer = analyzer.NewAnalyzerWithOriginalYAML(upstreamValues, downstreamValues, originalUpstreamYAML)
+	if len(upstream.Raw) > 0 {
 		log.Info().Msg("Using original YAML for enhanced comment detection")
 	} else {
-		valueAnalyzer = analyzer.NewAnalyzer(upstreamValues, downstreamValues)
 		log.Info().Msg("No original YAML available, comment detection will be limited")
 	}
 
-	// Analyze values
-	valueStatus := valueAnalyzer.Analyze()
+	var opts []analyzer.Option
+	if baselineFile != "" && !writeBaseline {
+		content, err := os.ReadFile(baselineFile)
+		if err != nil {
+			fatal(err, fmt.Sprintf("failed to read baseline: %s", baselineFile))
+		}
+		baseline, err := analyzer.ParseBaseline(content)
+		if err != nil {
+			fatal(err, fmt.Sprintf("invalid baseline: %s", baselineFile))
+		}
+		opts = append(opts, analyzer.WithBaseline(baseline))
+	}
+	if check {
+		opts = append(opts, analyzer.WithPolicy(checkPolicy()))
+	}
+
+	report, err := analyzeValues(upstream, downstreamContent, util.RelativeName(downstreamValuesFile, projectRoot), opts...)
+	if err != nil {
+		fatal(err, "failed to analyze values")
+	}
+	valueStatus := report.Status
+
+	// Record the findings as the new baseline, the baseline itself is not applied then
+	if writeBaseline {
+		if err := output.WriteBaseline(valueStatus, baselineFile); err != nil {
+			fatal(err, "failed to write baseline")
+		}
+		return
+	}
+	if baselineFile != "" {
+		log.Info().Msgf("Baseline %s hides %s", baselineFile, util.Count(len(report.Baselined), "known finding"))
+	}
+
+	// Diff mode prints the optimization as a patch instead of writing results
+	if printDiff {
+		if err := writeDiff(os.Stdout, valueStatus, downstreamContent); err != nil {
+			fatal(err, "failed to render diff")
+		}
+		return
+	}
+
+	// Check mode evaluates the policies instead of writing results
+	if check {
+		output.WriteCheckSummary(os.Stdout, valueStatus, report.Violations)
+		if len(report.Violations) > 0 {
+			os.Exit(exitCheckFailed)
+		}
+		return
+	}
 
 	// Create output manager and write results
 	outputMgr := output.NewManager(paths, outputFormat, optimize)
+	outputMgr.Policy = checkPolicy()
+	outputMgr.Original = downstreamContent
+	outputMgr.TemplatePath = templateFile
+	outputMgr.Metadata = output.Metadata{
+		Chart:        chartName,
+		ChartVersion: upstream.ChartVersion,
+		Release:      repo,
+		Upstream:     util.DisplayName(upstreamValuesFile),
+		Downstream:   util.DisplayName(downstreamValuesFile),
+	}
 	if err := outputMgr.WriteResults(valueStatus); err != nil {
-		log.Fatal().Err(err).Msg("Failed to write analysis results")
+		fatal(err, "Failed to write analysis results")
+	}
+}
+
+// checkPolicy builds the check policy from the command line flags
+func checkPolicy() analyzer.Policy {
+	return analyzer.Policy{
+		FailOnUnsupported:     failOnUnsupported,
+		FailOnTypeMismatch:    failOnTypeMismatch,
+		FailOnSchemaViolation: failOnSchemaViolation,
+		MaxRedundant:          maxRedundant,
+	}
+}
+
+// processHistory walks the revisions of a release and reports the user supplied values changed by each one
+func processHistory() {
+	if repo == "" {
+		log.Error().Msg("missing -repo flag, history mode requires a Helm release")
+		exitUsage()
+	}
+
+	log.Info().Msgf("Fetching history for Helm release: %s", repo)
+	helmClient, err := helm.NewClient(kubeContextName, namespace, kubeConfigFile)
+	if err != nil {
+		fatal(err, "fetching helm client")
+	}
+
+	revisions, err := helmClient.FetchReleaseHistory(repo)
+	if err != nil {
+		fatal(err, "fetching helm release history")
+	}
+
+	timeline := analyzer.Timeline{Release: repo}
+	previous := map[string]interface{}{}
+	for _, revision := range revisions {
+		current := revision.UserSupplied
+		if current == nil {
+			current = map[string]interface{}{}
+		}
+
+		timeline.Revisions = append(timeline.Revisions, analyzer.RevisionChanges{
+			Revision:    revision.Revision,
+			Updated:     revision.Updated,
+			Status:      revision.Status,
+			Chart:       revision.Chart,
+			AppVersion:  revision.AppVersion,
+			Description: revision.Description,
+			Changes:     analyzer.DiffValues(previous, current),
+		})
+		previous = current
+	}
+
+	log.Info().Msgf("Found %s for release %s", util.Count(len(timeline.Revisions), "revision"), repo)
+
+	if err := output.WriteTimeline(timeline, outputFormat, outDir); err != nil {
+		fatal(err, "Failed to write release history")
+	}
+}
+
+// processContextComparison fetches the same release from every kube context and reports the values that differ
+func processContextComparison() {
+	comparison := analyzer.ContextComparison{
+		Release:  repo,
+		Contexts: kubeContexts,
+	}
+
+	valueSets := make(map[string]map[string]interface{}, len(kubeContexts))
+	for _, kubeContext := range kubeContexts {
+		log.Info().Msgf("Fetching values of release %s from context: %s", repo, kubeContext)
+		helmClient, err := helm.NewClient(kubeContext, namespace, kubeConfigFile)
+		if err != nil {
+			fatal(err, fmt.Sprintf("fetching helm client for context %s", kubeContext))
+		}
+
+		values, err := helmClient.FetchReleaseUserValues(repo, revision)
+		if err != nil {
+			fatal(err, fmt.Sprintf("fetching helm release from context %s", kubeContext))
+		}
+
+		valueSets[kubeContext] = values
+	}
+
+	comparison.Differences = analyzer.CompareValueSets(kubeContexts, valueSets)
+
+	if err := output.WriteContextComparison(comparison, outputFormat, outDir); err != nil {
+		fatal(err, "Failed to write context comparison")
 	}
 }
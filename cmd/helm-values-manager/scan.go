@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"github.com/xunholy/helm-values-manager/pkg/helm"
+	"github.com/xunholy/helm-values-manager/pkg/output"
+	"github.com/xunholy/helm-values-manager/pkg/util"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// processScan analyzes the user supplied values of every listed release against its embedded chart
+func processScan() {
+	if scanWorkers < 1 {
+		log.Error().Msg("-workers must be at least 1")
+		exitUsage()
+	}
+
+	helmClient, err := helm.NewClient(kubeContextName, namespace, kubeConfigFile)
+	if err != nil {
+		fatal(err, "fetching helm client")
+	}
+
+	releases, err := helmClient.ListReleases(allNamespaces, selector)
+	if err != nil {
+		fatal(err, "listing helm releases")
+	}
+
+	// Keep the summary stable regardless of storage ordering
+	sort.Slice(releases, func(i, j int) bool {
+		if releases[i].Namespace != releases[j].Namespace {
+			return releases[i].Namespace < releases[j].Namespace
+		}
+		return releases[i].Name < releases[j].Name
+	})
+
+	log.Info().Msgf("Scanning %s with %s", util.Count(len(releases), "release"), util.Count(scanWorkers, "worker"))
+
+	// Analyze releases in a bounded worker pool, each worker writing into its own result slot
+	results := make([]output.ScanResult, len(releases))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < scanWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = scanRelease(releases[i])
+			}
+		}()
+	}
+
+	for i := range releases {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := output.WriteScanSummary(results, outDir); err != nil {
+		fatal(err, "Failed to write scan summary")
+	}
+}
+
+// scanRelease analyzes a single release and writes its detail files to a per-release directory
+func scanRelease(rel *release.Release) output.ScanResult {
+	result := output.ScanResult{
+		Namespace: rel.Namespace,
+		Release:   rel.Name,
+	}
+
+	if rel.Chart == nil {
+		result.Error = "release has no embedded chart"
+		return result
+	}
+
+	if rel.Chart.Metadata != nil {
+		result.Chart = fmt.Sprintf("%s-%s", rel.Chart.Metadata.Name, rel.Chart.Metadata.Version)
+	}
+
+	upstreamValues := rel.Chart.Values
+	if upstreamValues == nil {
+		upstreamValues = map[string]interface{}{}
+	}
+	downstreamValues := rel.Config
+	if downstreamValues == nil {
+		downstreamValues = map[string]interface{}{}
+	}
+
+	// The user supplied values are saved next to the reports, findings and diffs refer to that file
+	result.OutputDir = filepath.Join(outDir, rel.Namespace, rel.Name)
+	downstreamPath := filepath.Join(result.OutputDir, "user-values.yaml")
+	downstreamContent, err := yaml.Marshal(downstreamValues)
+	if err == nil {
+		err = util.CreateOutputFile(downstreamContent, downstreamPath)
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to write the user supplied values of release %s/%s", rel.Namespace, rel.Name)
+		result.Error = err.Error()
+		return result
+	}
+
+	upstream := upstreamSource{Values: upstreamValues, Raw: helm.RawChartValues(rel.Chart), Schema: rel.Chart.Schema}
+	report, err := analyzeValues(upstream, downstreamContent, downstreamPath)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to analyze release %s/%s", rel.Namespace, rel.Name)
+		result.Error = err.Error()
+		return result
+	}
+
+	valueStatus := report.Status
+	result.Redundant = analyzer.CountNestedKeys(valueStatus.Redundant)
+	result.Unsupported = analyzer.CountNestedKeys(valueStatus.Unsupported)
+	result.Commented = analyzer.CountNestedKeys(valueStatus.Commented)
+
+	// Write the per-release detail files
+	outputMgr := output.NewManager(analyzer.NewPathOptions(result.OutputDir), outputFormat, optimize)
+	outputMgr.Policy = checkPolicy()
+	outputMgr.Original = downstreamContent
+	outputMgr.Metadata = output.Metadata{
+		Release:      rel.Name,
+		ChartVersion: helm.ChartVersion(rel.Chart),
+		Downstream:   downstreamPath,
+	}
+	if rel.Chart.Metadata != nil {
+		outputMgr.Metadata.Chart = rel.Chart.Metadata.Name
+	}
+	if err := outputMgr.WriteResults(valueStatus); err != nil {
+		log.Error().Err(err).Msgf("Failed to write analysis results for release %s/%s", rel.Namespace, rel.Name)
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+)
+
+// runSchema prints a JSON Schema inferred from the upstream values
+func runSchema() {
+	if upstreamValuesFile == "" && chartName == "" {
+		log.Error().Msg("schema requires -upstream or -chart")
+		exitUsage()
+	}
+
+	upstream := loadUpstream(outDir, false)
+	if upstream.Raw == nil {
+		fatal(fmt.Errorf("%w: the raw values of %s are not available", analyzer.ErrInvalidValues, upstream.Name), "failed to generate schema")
+	}
+
+	schema, err := analyzer.GenerateSchema(upstream.Raw, chartName)
+	if err != nil {
+		fatal(err, "failed to parse values")
+	}
+
+	content, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		fatal(err, "failed to encode schema")
+	}
+	if _, err := fmt.Println(string(content)); err != nil {
+		fatal(writeFailed("schema", err), "failed to write schema")
+	}
+}
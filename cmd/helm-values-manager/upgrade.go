@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"github.com/xunholy/helm-values-manager/pkg/helm"
+	"github.com/xunholy/helm-values-manager/pkg/output"
+	"github.com/xunholy/helm-values-manager/pkg/util"
+)
+
+// runUpgrade reports how upgrading the upstream values to the -target-upstream file or the -target-version of
+// the chart affects the downstream values
+func runUpgrade() {
+	if downstreamValuesFile == "" {
+		log.Error().Msg("upgrade requires -downstream")
+		exitUsage()
+	}
+	if (targetValuesFile == "") == (targetVersion == "") || (targetVersion != "" && chartName == "") {
+		log.Error().Msg("upgrade requires either -target-upstream, or -target-version with -chart")
+		exitUsage()
+	}
+
+	current := loadUpstream(outDir, false)
+	targetName, targetValues := loadTarget()
+	_, downstreamValues, _ := loadDownstream()
+
+	currentName := current.Name
+	if current.ChartVersion != "" {
+		currentName = fmt.Sprintf("%s %s", chartName, current.ChartVersion)
+	}
+
+	changes := analyzer.PlanUpgrade(current.Values, targetValues, downstreamValues)
+	if err := output.WriteUpgrade(os.Stdout, changes, currentName, targetName, outputFormat); err != nil {
+		fatal(err, "failed to report upgrade")
+	}
+
+	if check {
+		for _, change := range changes {
+			if change.Impact == analyzer.ImpactRemoved || change.Impact == analyzer.ImpactTypeChanged {
+				os.Exit(exitCheckFailed)
+			}
+		}
+	}
+}
+
+// loadTarget loads the upstream values of the upgrade target and returns them with the name reports use for them
+func loadTarget() (string, map[string]interface{}) {
+	if targetValuesFile != "" {
+		content, err := util.ReadInput(targetValuesFile)
+		if err != nil {
+			fatal(err, fmt.Sprintf("failed to read target values file: %s", targetValuesFile))
+		}
+		values, _, err := analyzer.ParseValues(content)
+		if err != nil {
+			fatal(err, "failed to parse target values YAML")
+		}
+		return util.DisplayName(targetValuesFile), values
+	}
+
+	log.Info().Msgf("Fetching target values from chart: %s %s", chartName, targetVersion)
+	values, err := helm.FetchChartValues(chartName, targetVersion)
+	if err != nil {
+		fatal(err, "Unable to fetch target values from Helm chart")
+	}
+
+	name := fmt.Sprintf("%s %s", chartName, targetVersion)
+	if version, err := helm.FetchChartVersion(chartName, targetVersion); err != nil {
+		log.Warn().Err(err).Msg("Unable to resolve the target chart version")
+	} else if version != "" {
+		name = fmt.Sprintf("%s %s", chartName, version)
+	}
+	return name, values
+}
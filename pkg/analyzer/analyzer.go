@@ -3,29 +3,47 @@ package analyzer
 import (
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 // Analyzer is responsible for analyzing and comparing Helm values
 type Analyzer struct {
-	UpstreamValues       map[string]interface{}
-	DownstreamValues     map[string]interface{}
+	upstreamValues       map[string]interface{}
+	downstreamValues     map[string]interface{}
 	OriginalUpstreamYAML []byte
+	DownstreamFile       string
+	DownstreamPositions  Positions
+	UpstreamPositions    Positions
+	// OriginalDownstreamYAML is the downstream file content, read for inline hvm:keep and hvm:ignore annotations
+	// and for the anchors, aliases and merge keys to keep in the optimized values
+	OriginalDownstreamYAML []byte
+	// SkipAnnotations ignores the inline annotations of OriginalDownstreamYAML
+	SkipAnnotations bool
+	// StrictEmptyMaps analyzes the keys set under empty upstream maps, which are otherwise free-form and left untouched
+	StrictEmptyMaps bool
+	// IgnoreRules suppress the findings of matching paths and keep them in the optimized values
+	IgnoreRules []IgnoreRule
+	// SchemaViolations are reported as findings, see ValidateValues
+	SchemaViolations     []SchemaViolation
+	upstreamDescriptions map[string]string
+	activeRules          []IgnoreRule
 }
 
 // NewAnalyzer creates a new Analyzer with the given upstream and downstream values
 func NewAnalyzer(upstream, downstream map[string]interface{}) *Analyzer {
 	return &Analyzer{
-		UpstreamValues:   upstream,
-		DownstreamValues: downstream,
+		upstreamValues:   upstream,
+		downstreamValues: downstream,
 	}
 }
 
 // NewAnalyzerWithOriginalYAML creates a new Analyzer with the original YAML content
 func NewAnalyzerWithOriginalYAML(upstream, downstream map[string]interface{}, originalYAML []byte) *Analyzer {
 	return &Analyzer{
-		UpstreamValues:       upstream,
-		DownstreamValues:     downstream,
+		upstreamValues:       upstream,
+		downstreamValues:     downstream,
 		OriginalUpstreamYAML: originalYAML,
 	}
 }
@@ -37,18 +55,33 @@ func (a *Analyzer) Analyze() ValueStatus {
 		Unsupported: make(map[string]interface{}),
 		Commented:   make(map[string]interface{}),
 		Optimized:   make(map[string]interface{}),
+		Source:      a.DownstreamFile,
+	}
+
+	// Collect the upstream key descriptions so findings can explain what a key does
+	a.upstreamDescriptions = nil
+	if a.OriginalUpstreamYAML != nil {
+		if descriptions, err := ParseDescriptions(a.OriginalUpstreamYAML); err == nil {
+			a.upstreamDescriptions = descriptions
+		}
+	}
+
+	// Combine the configured ignore rules with the annotations of the downstream file
+	a.activeRules = append([]IgnoreRule(nil), a.IgnoreRules...)
+	if a.OriginalDownstreamYAML != nil && !a.SkipAnnotations {
+		if annotations, err := ParseAnnotations(a.OriginalDownstreamYAML, a.DownstreamFile); err == nil {
+			a.activeRules = append(a.activeRules, annotations...)
+		}
 	}
 
 	// First, create a deep copy of the downstream values for optimized output
-	for k, v := range a.DownstreamValues {
+	for k, v := range a.downstreamValues {
 		valueStatus.Optimized[k] = deepCopy(v)
 	}
 
 	// Process the values
-	a.detectValuesStatus("", a.UpstreamValues, a.DownstreamValues, &valueStatus)
-
-	// Special handling for service section which often has complex structures
-	a.handleServiceValues(&valueStatus)
+	a.detectValuesStatus("", a.upstreamValues, a.downstreamValues, &valueStatus)
+	a.detectSchemaViolations(&valueStatus)
 
 	// Remove unsupported values from optimized output
 	a.removeUnsupportedFromOptimized(&valueStatus)
@@ -57,50 +90,74 @@ func (a *Analyzer) Analyze() ValueStatus {
 	// These are technically supported (they exist in the chart) but are commented out
 	a.removeCommentedFromOptimized(&valueStatus)
 
+	// Record which downstream paths the optimization removes
+	for _, change := range DiffValues(a.downstreamValues, valueStatus.Optimized) {
+		if change.Type == ChangeRemoved {
+			valueStatus.Removed = append(valueStatus.Removed, change.Path)
+		}
+	}
+
+	// Keep the values shared through anchors consistent with the file
+	a.applyAnchors(&valueStatus)
+
+	// Report findings in a stable order
+	sort.Slice(valueStatus.Findings, func(i, j int) bool {
+		if valueStatus.Findings[i].Path != valueStatus.Findings[j].Path {
+			return valueStatus.Findings[i].Path < valueStatus.Findings[j].Path
+		}
+		return valueStatus.Findings[i].Category < valueStatus.Findings[j].Category
+	})
+	sort.SliceStable(valueStatus.Suppressed, func(i, j int) bool {
+		if valueStatus.Suppressed[i].Path != valueStatus.Suppressed[j].Path {
+			return valueStatus.Suppressed[i].Path < valueStatus.Suppressed[j].Path
+		}
+		return valueStatus.Suppressed[i].Category < valueStatus.Suppressed[j].Category
+	})
+
 	return valueStatus
 }
 
-// handleServiceValues gives special attention to service configurations
-func (a *Analyzer) handleServiceValues(status *ValueStatus) {
-	upService, hasUpService := a.UpstreamValues["service"].(map[string]interface{})
-	downService, hasDownService := a.DownstreamValues["service"].(map[string]interface{})
+// addFinding records a finding for a path, ignoring paths already reported in the same category.
+// It returns false when an ignore rule suppresses the finding, the value must then be left untouched.
+func (a *Analyzer) addFinding(status *ValueStatus, category Category, path string, value, upstreamValue interface{}) bool {
+	return a.recordFinding(status, Finding{Path: path, Category: category, Value: value, UpstreamValue: upstreamValue})
+}
 
-	if !hasUpService || !hasDownService {
-		return
+// recordFinding records a finding like addFinding, completing it with its file, positions and description
+func (a *Analyzer) recordFinding(status *ValueStatus, finding Finding) bool {
+	path, category := finding.Path, finding.Category
+	for _, existing := range status.Findings {
+		if existing.Path == path && existing.Category == category {
+			return true
+		}
 	}
-
-	// Extract service from optimized values for modification
-	optimizedService, hasOptimizedService := status.Optimized["service"].(map[string]interface{})
-	if !hasOptimizedService {
-		return
+	for _, suppressed := range status.Suppressed {
+		if suppressed.Path == path && suppressed.Category == category {
+			return false
+		}
 	}
 
-	for key, downVal := range downService {
-		upVal, exists := upService[key]
-		if !exists {
-			// This key is unsupported
-			if _, hasUnsupportedService := status.Unsupported["service"].(map[string]interface{}); !hasUnsupportedService {
-				status.Unsupported["service"] = make(map[string]interface{})
-			}
-			status.Unsupported["service"].(map[string]interface{})[key] = downVal
-		} else if equalValues(downVal, upVal) {
-			// This key is redundant
-			if _, hasRedundantService := status.Redundant["service"].(map[string]interface{}); !hasRedundantService {
-				status.Redundant["service"] = make(map[string]interface{})
-			}
-			status.Redundant["service"].(map[string]interface{})[key] = downVal
+	finding.File = a.DownstreamFile
 
-			// Remove from optimized
-			delete(optimizedService, key)
-		}
+	// Point the finding at the key in the source files when positions are known
+	if position, ok := a.DownstreamPositions[path]; ok {
+		finding.Line = position.Line
+		finding.Column = position.Column
+	}
+	if position, ok := a.UpstreamPositions[path]; ok {
+		finding.UpstreamLine = position.Line
 	}
+	finding.Description = a.upstreamDescriptions[path]
 
-	// Update optimized service section
-	if len(optimizedService) > 0 {
-		status.Optimized["service"] = optimizedService
-	} else {
-		delete(status.Optimized, "service")
+	for _, rule := range a.activeRules {
+		if rule.Matches(path, category) {
+			status.Suppressed = append(status.Suppressed, SuppressedFinding{Finding: finding, Rule: rule.String()})
+			return false
+		}
 	}
+
+	status.Findings = append(status.Findings, finding)
+	return true
 }
 
 // detectValuesStatus recursively compares upstream and downstream values
@@ -121,10 +178,14 @@ func (a *Analyzer) detectValuesStatus(path string, upstream, downstream map[stri
 			if isCommented {
 				// It's technically supported but commented out in the chart
 				// We'll add it to a new 'commented' category instead of unsupported
-				setNestedValue(status.Commented, currentPath, downVal)
+				if a.addFinding(status, CategoryCommented, currentPath, downVal, nil) {
+					setNestedValue(status.Commented, currentPath, downVal)
+				}
 			} else {
 				// Key in downstream doesn't exist in upstream, it's unsupported
-				setNestedValue(status.Unsupported, currentPath, downVal)
+				if a.addFinding(status, CategoryUnsupported, currentPath, downVal, nil) {
+					setNestedValue(status.Unsupported, currentPath, downVal)
+				}
 			}
 		}
 	}
@@ -145,15 +206,29 @@ func (a *Analyzer) detectValuesStatus(path string, upstream, downstream map[stri
 			continue // Already handled in first pass
 		}
 
+		// Flag values whose type differs from the upstream default
+		downKind, upKind := ValueKind(downVal), ValueKind(upVal)
+		if downKind != upKind && downKind != KindNull && !untypedDefault(upVal) && !numericCompatible(downVal, upVal) {
+			a.addFinding(status, CategoryTypeMismatch, currentPath, downVal, upVal)
+		}
+
 		// If both are maps, recurse
 		downMap, downIsMap := downVal.(map[string]interface{})
 		upMap, upIsMap := upVal.(map[string]interface{})
 
 		if downIsMap && upIsMap {
+			// An empty upstream map is a placeholder for free-form keys (annotations, resources, ...)
+			if len(upMap) == 0 && len(downMap) > 0 && !a.StrictEmptyMaps {
+				continue
+			}
+
 			// Recursively process nested maps
 			a.detectValuesStatus(currentPath, upMap, downMap, status)
 		} else if equalValues(downVal, upVal) {
-			// Values are the same, this is redundant
+			// Values are the same, this is redundant unless an ignore rule keeps it
+			if !a.addFinding(status, CategoryRedundant, currentPath, downVal, upVal) {
+				continue
+			}
 			setNestedValue(status.Redundant, currentPath, downVal)
 
 			// Remove redundant value from optimized map
@@ -162,6 +237,77 @@ func (a *Analyzer) detectValuesStatus(path string, upstream, downstream map[stri
 	}
 }
 
+// detectSchemaViolations reports the schema violations, one finding per path listing every rule it breaks
+func (a *Analyzer) detectSchemaViolations(status *ValueStatus) {
+	var paths []string
+	messages := make(map[string][]string)
+	for _, violation := range a.SchemaViolations {
+		if _, seen := messages[violation.Path]; !seen {
+			paths = append(paths, violation.Path)
+		}
+		messages[violation.Path] = append(messages[violation.Path], violation.Message)
+	}
+
+	for _, path := range paths {
+		value, _ := LookupValue(a.downstreamValues, path)
+		a.recordFinding(status, Finding{
+			Path:     path,
+			Category: CategorySchemaViolation,
+			Value:    value,
+			Message:  strings.Join(messages[path], "; "),
+		})
+	}
+}
+
+// Value kinds reported by ValueKind
+const (
+	KindNull   = "null"
+	KindMap    = "map"
+	KindList   = "list"
+	KindString = "string"
+	KindNumber = "number"
+	KindBool   = "bool"
+)
+
+// ValueKind returns the YAML kind of a value, treating all numeric types as numbers
+func ValueKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return KindNull
+	case map[string]interface{}, map[interface{}]interface{}:
+		return KindMap
+	case []interface{}:
+		return KindList
+	case string:
+		return KindString
+	case bool:
+		return KindBool
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return KindNumber
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// untypedDefault reports whether a default accepts values of any type. Charts use null and empty strings as
+// placeholders for values they do not set, such as a node port left to Kubernetes.
+func untypedDefault(value interface{}) bool {
+	return value == nil || value == ""
+}
+
+// numericCompatible reports whether a number and a numeric string are compared, templates render both the same way
+func numericCompatible(a, b interface{}) bool {
+	if ValueKind(a) == KindString {
+		a, b = b, a
+	}
+	text, isString := b.(string)
+	if !isString || ValueKind(a) != KindNumber {
+		return false
+	}
+	_, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	return err == nil
+}
+
 // CountNestedKeys counts all nested keys in a map (including nested maps)
 func CountNestedKeys(m map[string]interface{}) int {
 	count := 0
@@ -0,0 +1,467 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// anchorRule is the rule of the findings suppressed because their value is shared through an anchor
+const anchorRule = "kept for YAML aliases"
+
+// anchorIndex records where the keys and anchored nodes of a values file take effect once aliases and merge keys
+// are expanded
+type anchorIndex struct {
+	// keys maps each path to the key node providing its value
+	keys map[string]*yamlv3.Node
+	// appearances lists the paths at which each key node provides a value
+	appearances map[*yamlv3.Node][]string
+	// definitions is the path at which each key node is written
+	definitions map[*yamlv3.Node]string
+	// anchors is the path at which each anchored node is written
+	anchors map[*yamlv3.Node]string
+	// uses lists the paths whose value references each anchored node through an alias or merge key
+	uses map[*yamlv3.Node][]string
+}
+
+// keyValue is an effective key of a mapping, literal when it is written in the mapping rather than merged into it
+type keyValue struct {
+	key, value *yamlv3.Node
+	literal    bool
+}
+
+// indexAnchors walks a values document and records the effect of its anchors, returning nil when it has none
+func indexAnchors(document *yamlv3.Node) *anchorIndex {
+	if len(document.Content) == 0 || !hasAnchors(document) {
+		return nil
+	}
+
+	index := &anchorIndex{
+		keys:        make(map[string]*yamlv3.Node),
+		appearances: make(map[*yamlv3.Node][]string),
+		definitions: make(map[*yamlv3.Node]string),
+		anchors:     make(map[*yamlv3.Node]string),
+		uses:        make(map[*yamlv3.Node][]string),
+	}
+	index.walkValue("", document.Content[0], true)
+	return index
+}
+
+// hasAnchors reports whether a node or its children define an anchor
+func hasAnchors(node *yamlv3.Node) bool {
+	if node.Anchor != "" {
+		return true
+	}
+	for _, child := range node.Content {
+		if hasAnchors(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkValue records the keys and anchors of a value at a path
+func (x *anchorIndex) walkValue(path string, node *yamlv3.Node, literal bool) {
+	if node.Anchor != "" && literal {
+		x.anchors[node] = path
+	}
+
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		x.walkMapping(path, node, literal)
+	case yamlv3.SequenceNode:
+		// Keys of list items share the path of the list, only the anchors and aliases are recorded
+		for _, item := range node.Content {
+			if item.Kind == yamlv3.AliasNode {
+				x.uses[item.Alias] = append(x.uses[item.Alias], path)
+				continue
+			}
+			x.walkSequenceItem(path, item, literal)
+		}
+	}
+}
+
+// walkSequenceItem records the anchors and aliases of a list item at the path of the list
+func (x *anchorIndex) walkSequenceItem(path string, node *yamlv3.Node, literal bool) {
+	if node.Anchor != "" && literal {
+		x.anchors[node] = path
+	}
+	for _, child := range node.Content {
+		if child.Kind == yamlv3.AliasNode {
+			x.uses[child.Alias] = append(x.uses[child.Alias], path)
+			continue
+		}
+		x.walkSequenceItem(path, child, literal)
+	}
+}
+
+// walkMapping records the effective keys of a mapping, the keys written in it taking precedence over merged ones
+func (x *anchorIndex) walkMapping(path string, node *yamlv3.Node, literal bool) {
+	order, pairs := x.effectivePairs(path, node, literal)
+
+	for _, name := range order {
+		pair := pairs[name]
+		currentPath := joinPath(path, name)
+
+		x.keys[currentPath] = pair.key
+		x.appearances[pair.key] = append(x.appearances[pair.key], currentPath)
+		if _, defined := x.definitions[pair.key]; !defined && pair.literal {
+			x.definitions[pair.key] = currentPath
+		}
+
+		value, valueLiteral := pair.value, pair.literal
+		if value.Kind == yamlv3.AliasNode {
+			x.uses[value.Alias] = append(x.uses[value.Alias], currentPath)
+			value, valueLiteral = resolveAlias(value), false
+		}
+		x.walkValue(currentPath, value, valueLiteral)
+	}
+}
+
+// effectivePairs returns the keys of a mapping in order, with the key and value providing each of them.
+// Merged mappings are applied first, earlier ones taking precedence, as ParseValues does.
+func (x *anchorIndex) effectivePairs(path string, node *yamlv3.Node, literal bool) ([]string, map[string]keyValue) {
+	var order []string
+	pairs := make(map[string]keyValue)
+	set := func(name string, pair keyValue) {
+		if _, exists := pairs[name]; !exists {
+			order = append(order, name)
+		}
+		pairs[name] = pair
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Tag != "!!merge" {
+			continue
+		}
+
+		sources := []*yamlv3.Node{node.Content[i+1]}
+		if node.Content[i+1].Kind == yamlv3.SequenceNode {
+			sources = node.Content[i+1].Content
+		}
+		for j := len(sources) - 1; j >= 0; j-- {
+			source, sourceLiteral := sources[j], literal
+			if source.Kind == yamlv3.AliasNode {
+				x.uses[source.Alias] = append(x.uses[source.Alias], path)
+				source, sourceLiteral = resolveAlias(source), false
+			}
+			if source.Kind != yamlv3.MappingNode {
+				continue
+			}
+			if source.Anchor != "" && sourceLiteral {
+				x.anchors[source] = path
+			}
+
+			mergedOrder, merged := x.effectivePairs(path, source, sourceLiteral)
+			for _, name := range mergedOrder {
+				set(name, merged[name])
+			}
+		}
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Tag != "!!merge" {
+			set(node.Content[i].Value, keyValue{key: node.Content[i], value: node.Content[i+1], literal: literal})
+		}
+	}
+
+	return order, pairs
+}
+
+// removable reports whether a path can be removed from the file: every key written below it must only provide
+// removed values or values kept as anchor carriers, and every anchor defined below it must only be used by removed
+// values
+func (x *anchorIndex) removable(path string, removed, carriers map[string]bool) bool {
+	for keyPath, key := range x.keys {
+		if !isSameOrChild(keyPath, path) {
+			continue
+		}
+		for _, appearance := range x.appearances[key] {
+			if !isRemovedPath(appearance, removed) && !isRemovedPath(appearance, carriers) {
+				return false
+			}
+		}
+	}
+
+	for anchor, anchorPath := range x.anchors {
+		if !isSameOrChild(anchorPath, path) {
+			continue
+		}
+		for _, use := range x.uses[anchor] {
+			if !x.useRemoved(anchor, use, removed) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// useRemoved reports whether an anchored node is no longer used at a path, either because the path is removed or
+// because every key it merges there is removed
+func (x *anchorIndex) useRemoved(anchor *yamlv3.Node, use string, removed map[string]bool) bool {
+	if isRemovedPath(use, removed) {
+		return true
+	}
+	if anchor.Kind != yamlv3.MappingNode || len(anchor.Content) == 0 {
+		return false
+	}
+
+	for i := 0; i+1 < len(anchor.Content); i += 2 {
+		if anchor.Content[i].Tag == "!!merge" {
+			continue
+		}
+		if !isRemovedPath(joinPath(use, anchor.Content[i].Value), removed) {
+			return false
+		}
+	}
+	return true
+}
+
+// applyAnchors renders the optimized values from the downstream file so that its comments and anchors are kept.
+// The optimization of a file using anchors, aliases or merge keys is kept consistent with them: a value shared
+// through an anchor is only removed when every copy of it is removed, the findings of the values kept for that
+// reason are suppressed, and findings on copies are attributed to the anchor definition.
+func (a *Analyzer) applyAnchors(status *ValueStatus) {
+	if a.OriginalDownstreamYAML == nil {
+		return
+	}
+
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(a.OriginalDownstreamYAML, &document); err != nil {
+		return
+	}
+	if len(document.Content) == 0 {
+		return
+	}
+
+	removed := make(map[string]bool, len(status.Removed))
+	for _, path := range status.Removed {
+		removed[path] = true
+	}
+
+	index := indexAnchors(&document)
+	if index == nil {
+		// Without anchors every removed key is deleted where it is written
+		status.OptimizedDocument = renderOptimizedValues(&document, nil, removed, status.Optimized)
+		return
+	}
+
+	// A removed value still used through an alias or merge key is kept as the carrier of the anchor.
+	// Keeping it can make other removals unsafe, repeat until nothing changes.
+	carriers := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, path := range status.Removed {
+			if !removed[path] || index.removable(path, removed, carriers) {
+				continue
+			}
+			delete(removed, path)
+			carriers[path] = true
+			changed = true
+
+			if value, ok := LookupValue(a.downstreamValues, path); ok {
+				_ = SetValue(status.Optimized, path, deepCopy(value))
+			}
+		}
+	}
+
+	if len(carriers) > 0 {
+		// Carriers only keep the keys still used by their copies
+		var trimmed []string
+		for keyPath, key := range index.keys {
+			definition := index.definitions[key]
+			if definition != keyPath || isRemovedPath(keyPath, removed) || carriers[keyPath] || !isRemovedPath(keyPath, carriers) {
+				continue
+			}
+			if index.removable(keyPath, removed, carriers) {
+				trimmed = append(trimmed, keyPath)
+			}
+		}
+		sort.Strings(trimmed)
+		for _, path := range trimmed {
+			if isRemovedPath(path, removed) {
+				continue
+			}
+			removed[path] = true
+			DeleteValue(status.Optimized, path)
+		}
+
+		status.Removed = status.Removed[:0]
+		for path := range removed {
+			status.Removed = append(status.Removed, path)
+		}
+		sort.Strings(status.Removed)
+
+		findings := status.Findings[:0]
+		for _, finding := range status.Findings {
+			if finding.Category != CategoryTypeMismatch && finding.Category != CategorySchemaViolation && isRemovedPath(finding.Path, carriers) && !isRemovedPath(finding.Path, removed) {
+				status.Suppressed = append(status.Suppressed, SuppressedFinding{Finding: finding, Rule: anchorRule})
+				continue
+			}
+			findings = append(findings, finding)
+		}
+		status.Findings = findings
+	}
+
+	for i, finding := range status.Findings {
+		if key, ok := index.keys[finding.Path]; ok {
+			if definition := index.definitions[key]; definition != "" && definition != finding.Path {
+				status.Findings[i].Anchor = definition
+			}
+		}
+	}
+
+	status.OptimizedDocument = renderOptimizedValues(&document, index, removed, status.Optimized)
+}
+
+// renderOptimizedValues renders the optimized document, or returns nil when it does not hold the optimized values
+// so that they are encoded without the comments of the file instead
+func renderOptimizedValues(document *yamlv3.Node, index *anchorIndex, removed map[string]bool, optimized map[string]interface{}) []byte {
+	content, err := renderOptimizedDocument(document, index, removed)
+	if err != nil {
+		return nil
+	}
+	values, _, err := ParseValues(content)
+	if err != nil || !equalValues(values, optimized) {
+		return nil
+	}
+	return content
+}
+
+// renderOptimizedDocument deletes the removed keys from the downstream document where they are written, drops the
+// merge keys left without a mapping to merge and encodes the result. A nil index is a document without anchors.
+func renderOptimizedDocument(document *yamlv3.Node, index *anchorIndex, removed map[string]bool) ([]byte, error) {
+	deleted := make(map[*yamlv3.Node]bool)
+	for path := range removed {
+		if index == nil {
+			deleteKey(document.Content[0], strings.Split(path, "."), deleted)
+			continue
+		}
+		key := index.keys[path]
+		if key == nil || index.definitions[key] != path {
+			// Copies of anchored values disappear with their definition
+			continue
+		}
+		deleteKey(document.Content[0], strings.Split(path, "."), deleted)
+	}
+	dropDeletedAliases(document.Content[0], deleted)
+	clearMergeTags(document.Content[0])
+
+	var b bytes.Buffer
+	encoder := yamlv3.NewEncoder(&b)
+	encoder.SetIndent(FormatIndent)
+	if err := encoder.Encode(document); err != nil {
+		return nil, fmt.Errorf("failed to encode optimized values: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode optimized values: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// deleteKey removes the key written at a path, following only the keys written in each mapping
+func deleteKey(node *yamlv3.Node, parts []string, deleted map[*yamlv3.Node]bool) {
+	if node.Kind != yamlv3.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Tag == "!!merge" || node.Content[i].Value != parts[0] {
+			continue
+		}
+		if len(parts) > 1 {
+			deleteKey(node.Content[i+1], parts[1:], deleted)
+			return
+		}
+
+		markDeleted(node.Content[i+1], deleted)
+		node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		return
+	}
+}
+
+// markDeleted records a deleted node and its children, so that aliases referencing them can be dropped
+func markDeleted(node *yamlv3.Node, deleted map[*yamlv3.Node]bool) {
+	deleted[node] = true
+	for _, child := range node.Content {
+		markDeleted(child, deleted)
+	}
+}
+
+// dropDeletedAliases removes the merge keys and list items referencing deleted anchors
+func dropDeletedAliases(node *yamlv3.Node, deleted map[*yamlv3.Node]bool) {
+	isDeleted := func(n *yamlv3.Node) bool {
+		return n.Kind == yamlv3.AliasNode && deleted[n.Alias]
+	}
+
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag == "!!merge" {
+				if value.Kind == yamlv3.SequenceNode {
+					value.Content = filterNodes(value.Content, isDeleted)
+					if len(value.Content) == 0 {
+						continue
+					}
+				} else if isDeleted(value) {
+					continue
+				}
+			}
+			content = append(content, key, value)
+		}
+		node.Content = content
+	case yamlv3.SequenceNode:
+		node.Content = filterNodes(node.Content, isDeleted)
+	}
+
+	for _, child := range node.Content {
+		dropDeletedAliases(child, deleted)
+	}
+}
+
+// clearMergeTags clears the tag of merge keys, yaml.v3 writes them as "!!merge <<" otherwise
+func clearMergeTags(node *yamlv3.Node) {
+	for i, child := range node.Content {
+		if node.Kind == yamlv3.MappingNode && i%2 == 0 && child.Tag == "!!merge" {
+			child.Tag = ""
+		}
+		clearMergeTags(child)
+	}
+}
+
+// filterNodes returns the nodes not matching drop
+func filterNodes(nodes []*yamlv3.Node, drop func(*yamlv3.Node) bool) []*yamlv3.Node {
+	kept := nodes[:0]
+	for _, node := range nodes {
+		if !drop(node) {
+			kept = append(kept, node)
+		}
+	}
+	return kept
+}
+
+// isSameOrChild reports whether a path is the given parent path or below it
+func isSameOrChild(path, parent string) bool {
+	return path == parent || strings.HasPrefix(path, parent+".")
+}
+
+// isRemovedPath reports whether a path or one of its parents is in the removed set
+func isRemovedPath(path string, removed map[string]bool) bool {
+	for {
+		if removed[path] {
+			return true
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+const anchorsUpstream = `resources:
+  limits:
+    cpu: 100m
+  requests:
+    cpu: 100m
+worker:
+  resources:
+    limits:
+      cpu: 500m
+`
+
+func TestAnalyzeAnchors(t *testing.T) {
+	tests := []struct {
+		name           string
+		downstream     string
+		wantFindings   []string
+		wantSuppressed []string
+		wantRemoved    []string
+		// wantAnchors maps the findings on copies to the path of their anchor definition
+		wantAnchors  map[string]string
+		wantDocument string
+	}{
+		{
+			name:           "without anchors",
+			downstream:     "# Limits\nresources:\n  limits:\n    cpu: 100m # default\n  requests:\n    cpu: 200m # hvm:keep\n",
+			wantFindings:   []string{"resources.limits.cpu=redundant"},
+			wantSuppressed: []string{},
+			wantRemoved:    []string{"resources.limits"},
+			wantDocument:   "# Limits\nresources:\n  requests:\n    cpu: 200m # hvm:keep\n",
+		},
+		{
+			name:           "alias of a redundant value",
+			downstream:     "resources:\n  limits: &limits\n    cpu: 100m\n  requests: *limits\n",
+			wantFindings:   []string{"resources.limits.cpu=redundant", "resources.requests.cpu=redundant"},
+			wantSuppressed: []string{},
+			wantRemoved:    []string{"resources.limits", "resources.requests"},
+			wantAnchors:    map[string]string{"resources.requests.cpu": "resources.limits.cpu"},
+			wantDocument:   "resources: {}\n",
+		},
+		{
+			name:           "anchor still used by a copy",
+			downstream:     "resources:\n  limits:\n    cpu: &cpu 100m\nworker:\n  resources:\n    limits:\n      cpu: *cpu\n",
+			wantFindings:   []string{},
+			wantSuppressed: []string{"resources.limits.cpu=redundant"},
+			wantRemoved:    []string{},
+			wantDocument:   "resources:\n  limits:\n    cpu: &cpu 100m\nworker:\n  resources:\n    limits:\n      cpu: *cpu\n",
+		},
+		{
+			name:           "merge keys of removed values",
+			downstream:     "defaults: &defaults\n  cpu: 100m\nresources:\n  limits:\n    <<: *defaults\n  requests:\n    <<: *defaults\n",
+			wantFindings:   []string{"defaults=unsupported", "resources.limits.cpu=redundant", "resources.requests.cpu=redundant"},
+			wantSuppressed: []string{},
+			wantRemoved:    []string{"defaults", "resources.limits", "resources.requests"},
+			wantAnchors: map[string]string{
+				"resources.limits.cpu":   "defaults.cpu",
+				"resources.requests.cpu": "defaults.cpu",
+			},
+			wantDocument: "resources: {}\n",
+		},
+		{
+			name:           "merge key still used by a copy",
+			downstream:     "defaults: &defaults\n  cpu: 100m\nresources:\n  limits:\n    <<: *defaults\nworker:\n  resources:\n    limits:\n      <<: *defaults\n",
+			wantFindings:   []string{},
+			wantSuppressed: []string{"defaults=unsupported", "resources.limits.cpu=redundant"},
+			wantRemoved:    []string{},
+			wantDocument:   "defaults: &defaults\n  cpu: 100m\nresources:\n  limits:\n    <<: *defaults\nworker:\n  resources:\n    limits:\n      <<: *defaults\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Analyze(context.Background(), []byte(anchorsUpstream), []byte(tt.downstream))
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+
+			if got := findingKeys(report.Findings); !reflect.DeepEqual(got, tt.wantFindings) {
+				t.Errorf("findings = %v, want %v", got, tt.wantFindings)
+			}
+			if got := findingKeys(suppressedFindings(report.Suppressed)); !reflect.DeepEqual(got, tt.wantSuppressed) {
+				t.Errorf("suppressed = %v, want %v", got, tt.wantSuppressed)
+			}
+			if !reflect.DeepEqual(report.Removed, tt.wantRemoved) {
+				t.Errorf("removed = %v, want %v", report.Removed, tt.wantRemoved)
+			}
+			for _, finding := range report.Findings {
+				if finding.Anchor != tt.wantAnchors[finding.Path] {
+					t.Errorf("%s anchor = %q, want %q", finding.Path, finding.Anchor, tt.wantAnchors[finding.Path])
+				}
+			}
+			if got := string(report.Status.OptimizedDocument); got != tt.wantDocument {
+				t.Errorf("optimized document =\n%s\nwant\n%s", got, tt.wantDocument)
+			}
+		})
+	}
+}
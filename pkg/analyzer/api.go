@@ -0,0 +1,217 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+)
+
+// Report is the result of Analyze
+type Report struct {
+	// Summary holds the number of reported findings per category
+	Summary Summary
+	// Findings lists the reported findings sorted by path
+	Findings []Finding
+	// Suppressed lists the findings hidden by ignore rules or inline annotations
+	Suppressed []SuppressedFinding
+	// Baselined lists the findings hidden because the baseline already knows them
+	Baselined []Finding
+	// Violations lists the policies the findings violate, empty unless WithPolicy is used
+	Violations []Violation
+	// Optimized holds the downstream values without the redundant, unsupported and commented entries
+	Optimized map[string]interface{}
+	// Removed lists the downstream paths dropped from the optimized values
+	Removed []string
+	// Status holds the complete analysis results, as consumed by the output writers
+	Status ValueStatus
+	// Explanation describes the key selected with WithExplain
+	Explanation *Explanation
+}
+
+// Option configures Analyze
+type Option func(*options)
+
+type options struct {
+	sourceName          string
+	ignoreRules         []IgnoreRule
+	inlineAnnotations   bool
+	commentDetection    bool
+	freeFormMaps        bool
+	baseline            *Baseline
+	policy              *Policy
+	upstreamPositions   bool
+	downstreamPositions bool
+	explainPath         string
+	schema              []byte
+}
+
+// WithSourceName sets the file name findings refer to, usually the path of the downstream file
+func WithSourceName(name string) Option {
+	return func(o *options) {
+		o.sourceName = name
+	}
+}
+
+// WithIgnoreRules suppresses the findings matching any of the rules
+func WithIgnoreRules(rules ...IgnoreRule) Option {
+	return func(o *options) {
+		o.ignoreRules = append(o.ignoreRules, rules...)
+	}
+}
+
+// WithInlineAnnotations enables or disables the hvm:keep and hvm:ignore comments of the downstream values, enabled by default
+func WithInlineAnnotations(enabled bool) Option {
+	return func(o *options) {
+		o.inlineAnnotations = enabled
+	}
+}
+
+// WithCommentDetection enables or disables the detection of keys commented out in the upstream values
+// and of their helm-docs descriptions, enabled by default
+func WithCommentDetection(enabled bool) Option {
+	return func(o *options) {
+		o.commentDetection = enabled
+	}
+}
+
+// WithFreeFormMaps enables or disables leaving the keys set under empty upstream maps unchecked, enabled by default.
+// Charts declare free-form sections such as podAnnotations or resources as empty maps and pass their keys through.
+func WithFreeFormMaps(enabled bool) Option {
+	return func(o *options) {
+		o.freeFormMaps = enabled
+	}
+}
+
+// WithPositions enables or disables reporting the line and column of findings, enabled by default.
+// Disable it for values that were not read from a file, where positions are meaningless.
+func WithPositions(upstream, downstream bool) Option {
+	return func(o *options) {
+		o.upstreamPositions = upstream
+		o.downstreamPositions = downstream
+	}
+}
+
+// WithBaseline hides the findings already known to the baseline
+func WithBaseline(baseline Baseline) Option {
+	return func(o *options) {
+		o.baseline = &baseline
+	}
+}
+
+// WithPolicy evaluates the reported findings against a policy and fills Report.Violations
+func WithPolicy(policy Policy) Option {
+	return func(o *options) {
+		o.policy = &policy
+	}
+}
+
+// WithExplain describes a key of the upstream values in Report.Explanation, with how the downstream values set it
+// unless they are nil
+func WithExplain(path string) Option {
+	return func(o *options) {
+		o.explainPath = path
+	}
+}
+
+// WithSchema validates the downstream values merged with the upstream defaults against a JSON Schema, usually
+// the values.schema.json of the chart, and reports the rejected values as schema-violation findings
+func WithSchema(schema []byte) Option {
+	return func(o *options) {
+		o.schema = schema
+	}
+}
+
+// Analyze compares downstream values with the upstream defaults of a chart. Both are the YAML content of
+// a values file. It does not log or write anything, errors are returned to the caller. Downstream values
+// defining a key more than once are rejected with a DuplicateKeysError.
+func Analyze(ctx context.Context, upstream, downstream []byte, opts ...Option) (*Report, error) {
+	o := options{
+		inlineAnnotations:   true,
+		commentDetection:    true,
+		freeFormMaps:        true,
+		upstreamPositions:   true,
+		downstreamPositions: true,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	upstreamValues, upstreamPositions, err := ParseValues(upstream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse upstream values: %w", err)
+	}
+
+	downstreamValues, downstreamPositions, err := ParseValues(downstream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse downstream values: %w", err)
+	}
+
+	// Only the last definition of a duplicate key would be analyzed, the others silently lost
+	if duplicates, err := FindDuplicateKeys(downstream); err == nil && len(duplicates) > 0 {
+		return nil, &DuplicateKeysError{File: o.sourceName, Duplicates: duplicates}
+	}
+
+	valueAnalyzer := NewAnalyzer(upstreamValues, downstreamValues)
+	valueAnalyzer.DownstreamFile = o.sourceName
+	valueAnalyzer.IgnoreRules = o.ignoreRules
+	valueAnalyzer.StrictEmptyMaps = !o.freeFormMaps
+	if o.commentDetection {
+		valueAnalyzer.OriginalUpstreamYAML = upstream
+	}
+	if o.upstreamPositions {
+		valueAnalyzer.UpstreamPositions = upstreamPositions
+	}
+	if o.downstreamPositions {
+		valueAnalyzer.DownstreamPositions = downstreamPositions
+	}
+	if o.inlineAnnotations {
+		// Malformed annotations are an error for library callers rather than silently skipped
+		if _, err := ParseAnnotations(downstream, o.sourceName); err != nil {
+			return nil, fmt.Errorf("invalid annotation in downstream values: %w", err)
+		}
+	} else {
+		valueAnalyzer.SkipAnnotations = true
+	}
+	valueAnalyzer.OriginalDownstreamYAML = downstream
+	if o.schema != nil {
+		if valueAnalyzer.SchemaViolations, err = ValidateValues(o.schema, upstreamValues, downstreamValues); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	status := valueAnalyzer.Analyze()
+	if o.baseline != nil {
+		status = o.baseline.Apply(status)
+	}
+
+	report := &Report{
+		Summary:    status.Summarize(),
+		Findings:   status.Findings,
+		Suppressed: status.Suppressed,
+		Baselined:  status.Baselined,
+		Optimized:  status.Optimized,
+		Removed:    status.Removed,
+		Status:     status,
+	}
+	if o.policy != nil {
+		report.Violations = o.policy.Evaluate(status)
+	}
+	if o.explainPath != "" {
+		var explanation Explanation
+		if downstream == nil {
+			explanation = valueAnalyzer.explain(o.explainPath, nil)
+		} else {
+			explanation = valueAnalyzer.explain(o.explainPath, &status)
+		}
+		report.Explanation = &explanation
+	}
+
+	return report, nil
+}
@@ -0,0 +1,321 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testUpstream = `image:
+  # -- Image tag
+  tag: "1.0"
+  pullPolicy: IfNotPresent
+replicas: 1
+podAnnotations: {}
+service:
+  port: 80
+  nodePort: ""
+  # type: ClusterIP
+`
+
+const testDownstream = `image:
+  tag: "1.0"
+replicas: true
+podAnnotations:
+  team: web
+service:
+  type: NodePort
+  nodePort: 30080
+extra: value # hvm:ignore unsupported
+`
+
+// findingKeys lists the findings of a report as path=category
+func findingKeys(findings []Finding) []string {
+	keys := []string{}
+	for _, finding := range findings {
+		keys = append(keys, finding.Path+"="+string(finding.Category))
+	}
+	return keys
+}
+
+func TestAnalyzeOptions(t *testing.T) {
+	allFindings := []string{
+		"image.tag=redundant",
+		"replicas=type-mismatch",
+		"service.type=commented",
+	}
+
+	tests := []struct {
+		name       string
+		downstream string
+		opts       []Option
+		check      func(t *testing.T, report *Report)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, report *Report) {
+				if got := findingKeys(report.Findings); !reflect.DeepEqual(got, allFindings) {
+					t.Errorf("findings = %v, want %v", got, allFindings)
+				}
+				if got := findingKeys(suppressedFindings(report.Suppressed)); !reflect.DeepEqual(got, []string{"extra=unsupported"}) {
+					t.Errorf("suppressed = %v, want the annotated extra key", got)
+				}
+				if report.Findings[0].Line != 2 || report.Findings[0].UpstreamLine != 3 {
+					t.Errorf("image.tag positions = %d/%d, want 2/3", report.Findings[0].Line, report.Findings[0].UpstreamLine)
+				}
+				if report.Findings[0].Description != "Image tag" {
+					t.Errorf("image.tag description = %q, want the helm-docs comment", report.Findings[0].Description)
+				}
+				if _, kept := LookupValue(report.Optimized, "podAnnotations.team"); !kept {
+					t.Errorf("keys under an empty upstream map must be kept in the optimized values")
+				}
+				if report.Violations != nil || report.Explanation != nil {
+					t.Errorf("violations and explanation are only set by their options")
+				}
+			},
+		},
+		{
+			name: "source name",
+			opts: []Option{WithSourceName("values.yaml")},
+			check: func(t *testing.T, report *Report) {
+				for _, finding := range report.Findings {
+					if finding.File != "values.yaml" {
+						t.Errorf("%s file = %q, want values.yaml", finding.Path, finding.File)
+					}
+				}
+			},
+		},
+		{
+			name: "ignore rules",
+			opts: []Option{WithIgnoreRules(IgnoreRule{Pattern: "image", Categories: []Category{CategoryRedundant}})},
+			check: func(t *testing.T, report *Report) {
+				want := []string{"replicas=type-mismatch", "service.type=commented"}
+				if got := findingKeys(report.Findings); !reflect.DeepEqual(got, want) {
+					t.Errorf("findings = %v, want %v", got, want)
+				}
+				if _, kept := LookupValue(report.Optimized, "image.tag"); !kept {
+					t.Errorf("ignored values must be kept in the optimized values")
+				}
+			},
+		},
+		{
+			name: "inline annotations disabled",
+			opts: []Option{WithInlineAnnotations(false)},
+			check: func(t *testing.T, report *Report) {
+				want := append([]string{"extra=unsupported"}, allFindings...)
+				if got := findingKeys(report.Findings); !reflect.DeepEqual(got, want) {
+					t.Errorf("findings = %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name: "comment detection disabled",
+			opts: []Option{WithCommentDetection(false)},
+			check: func(t *testing.T, report *Report) {
+				want := []string{"image.tag=redundant", "replicas=type-mismatch", "service.type=unsupported"}
+				if got := findingKeys(report.Findings); !reflect.DeepEqual(got, want) {
+					t.Errorf("findings = %v, want %v", got, want)
+				}
+				if report.Findings[0].Description != "" {
+					t.Errorf("descriptions require comment detection")
+				}
+			},
+		},
+		{
+			name: "free-form maps disabled",
+			opts: []Option{WithFreeFormMaps(false)},
+			check: func(t *testing.T, report *Report) {
+				want := []string{"image.tag=redundant", "podAnnotations.team=unsupported", "replicas=type-mismatch", "service.type=commented"}
+				if got := findingKeys(report.Findings); !reflect.DeepEqual(got, want) {
+					t.Errorf("findings = %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name: "positions disabled",
+			opts: []Option{WithPositions(false, false)},
+			check: func(t *testing.T, report *Report) {
+				for _, finding := range report.Findings {
+					if finding.Line != 0 || finding.Column != 0 || finding.UpstreamLine != 0 {
+						t.Errorf("%s has a position without WithPositions", finding.Path)
+					}
+				}
+			},
+		},
+		{
+			name: "baseline",
+			opts: []Option{WithBaseline(Baseline{Findings: []BaselineEntry{
+				{Fingerprint: Finding{Path: "replicas", Category: CategoryTypeMismatch}.Fingerprint()},
+			}})},
+			check: func(t *testing.T, report *Report) {
+				if got := findingKeys(report.Baselined); !reflect.DeepEqual(got, []string{"replicas=type-mismatch"}) {
+					t.Errorf("baselined = %v, want replicas", got)
+				}
+				if report.Summary.TypeMismatch != 0 {
+					t.Errorf("baselined findings must not be counted")
+				}
+			},
+		},
+		{
+			name: "policy",
+			opts: []Option{WithPolicy(Policy{FailOnUnsupported: true, FailOnTypeMismatch: true, MaxRedundant: -1})},
+			check: func(t *testing.T, report *Report) {
+				if len(report.Violations) != 1 || report.Violations[0].Category != CategoryTypeMismatch {
+					t.Fatalf("violations = %v, want the type mismatch policy only", report.Violations)
+				}
+				if want := "found 1 value with a type mismatch"; report.Violations[0].Message != want {
+					t.Errorf("message = %q, want %q", report.Violations[0].Message, want)
+				}
+			},
+		},
+		{
+			name: "schema",
+			opts: []Option{
+				WithSchema([]byte(`{"required": ["name"], "properties": {"replicas": {"type": "integer"}}}`)),
+				WithPolicy(Policy{FailOnSchemaViolation: true, MaxRedundant: -1}),
+			},
+			check: func(t *testing.T, report *Report) {
+				want := []string{
+					"image.tag=redundant",
+					"name=schema-violation",
+					"replicas=schema-violation",
+					"replicas=type-mismatch",
+					"service.type=commented",
+				}
+				if got := findingKeys(report.Findings); !reflect.DeepEqual(got, want) {
+					t.Errorf("findings = %v, want %v", got, want)
+				}
+				if len(report.Violations) != 1 || report.Violations[0].Category != CategorySchemaViolation {
+					t.Errorf("violations = %v, want the schema violation policy only", report.Violations)
+				}
+				if report.Summary.SchemaViolation != 2 {
+					t.Errorf("schema violations = %d, want 2", report.Summary.SchemaViolation)
+				}
+			},
+		},
+		{
+			name: "explain",
+			opts: []Option{WithExplain("image.tag")},
+			check: func(t *testing.T, report *Report) {
+				explanation := report.Explanation
+				if explanation == nil {
+					t.Fatal("explanation is not set")
+				}
+				if !explanation.Upstream || explanation.Default != "1.0" || explanation.Description != "Image tag" {
+					t.Errorf("explanation = %+v, want the upstream default and description", explanation)
+				}
+				if !explanation.DownstreamSet || len(explanation.Findings) != 1 {
+					t.Errorf("explanation = %+v, want the downstream value and its finding", explanation)
+				}
+			},
+		},
+		{
+			name:       "explain without downstream values",
+			downstream: "-",
+			opts:       []Option{WithExplain("service.type")},
+			check: func(t *testing.T, report *Report) {
+				explanation := report.Explanation
+				if explanation == nil || !explanation.Commented || explanation.DownstreamSet {
+					t.Errorf("explanation = %+v, want the commented-out upstream key only", explanation)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var downstream []byte
+			switch tt.downstream {
+			case "":
+				downstream = []byte(testDownstream)
+			case "-":
+				downstream = nil
+			default:
+				downstream = []byte(tt.downstream)
+			}
+
+			report, err := Analyze(context.Background(), []byte(testUpstream), downstream, tt.opts...)
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			tt.check(t, report)
+		})
+	}
+}
+
+func TestAnalyzeErrors(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		upstream   string
+		downstream string
+		opts       []Option
+		want       error
+	}{
+		{
+			name:       "invalid upstream",
+			upstream:   "image: [",
+			downstream: testDownstream,
+			want:       ErrInvalidValues,
+		},
+		{
+			name:       "duplicate keys",
+			upstream:   testUpstream,
+			downstream: "replicas: 1\nreplicas: 2\n",
+			want:       ErrDuplicateKeys,
+		},
+		{
+			name:       "invalid annotation",
+			upstream:   testUpstream,
+			downstream: "replicas: 2 # hvm:ignore\n",
+			want:       ErrInvalidAnnotation,
+		},
+		{
+			name:       "invalid annotation skipped",
+			upstream:   testUpstream,
+			downstream: "replicas: 2 # hvm:ignore\n",
+			opts:       []Option{WithInlineAnnotations(false)},
+		},
+		{
+			name:       "invalid schema",
+			upstream:   testUpstream,
+			downstream: testDownstream,
+			opts:       []Option{WithSchema([]byte("{"))},
+			want:       ErrInvalidSchema,
+		},
+		{
+			name:       "cancelled context",
+			ctx:        cancelled,
+			upstream:   testUpstream,
+			downstream: testDownstream,
+			want:       context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			_, err := Analyze(ctx, []byte(tt.upstream), []byte(tt.downstream), tt.opts...)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Analyze() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// suppressedFindings returns the findings of suppressed findings
+func suppressedFindings(suppressed []SuppressedFinding) []Finding {
+	findings := make([]Finding, 0, len(suppressed))
+	for _, finding := range suppressed {
+		findings = append(findings, finding.Finding)
+	}
+	return findings
+}
@@ -0,0 +1,125 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// BaselineVersion is the version of the baseline file format
+const BaselineVersion = 1
+
+// Baseline records the findings known at some point, so that only new findings are reported
+type Baseline struct {
+	Version  int             `yaml:"version"`
+	Findings []BaselineEntry `yaml:"findings"`
+}
+
+// BaselineEntry identifies a known finding. The file, path and category are kept for readability,
+// only the fingerprint is used for matching.
+type BaselineEntry struct {
+	Fingerprint string   `yaml:"fingerprint"`
+	File        string   `yaml:"file,omitempty"`
+	Path        string   `yaml:"path"`
+	Category    Category `yaml:"category"`
+}
+
+// Fingerprint identifies a finding by its file, path and category, independently of its value and position.
+// The file should be relative to the project, an absolute path would tie the fingerprint to one checkout.
+func (f Finding) Fingerprint() string {
+	file := ""
+	if f.File != "" {
+		file = filepath.ToSlash(filepath.Clean(f.File))
+	}
+
+	sum := sha256.Sum256([]byte(file + "\x00" + f.Path + "\x00" + string(f.Category)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// NewBaseline records the current findings of an analysis
+func NewBaseline(valueStatus ValueStatus) Baseline {
+	baseline := Baseline{Version: BaselineVersion, Findings: []BaselineEntry{}}
+
+	// Findings already hidden by an earlier baseline stay known
+	findings := append(append([]Finding(nil), valueStatus.Findings...), valueStatus.Baselined...)
+	seen := make(map[string]bool)
+	for _, finding := range findings {
+		fingerprint := finding.Fingerprint()
+		if seen[fingerprint] {
+			continue
+		}
+		seen[fingerprint] = true
+
+		baseline.Findings = append(baseline.Findings, BaselineEntry{
+			Fingerprint: fingerprint,
+			File:        finding.File,
+			Path:        finding.Path,
+			Category:    finding.Category,
+		})
+	}
+
+	sort.Slice(baseline.Findings, func(i, j int) bool {
+		a, b := baseline.Findings[i], baseline.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Category < b.Category
+	})
+
+	return baseline
+}
+
+// ParseBaseline parses the content of a baseline file
+func ParseBaseline(content []byte) (Baseline, error) {
+	var baseline Baseline
+	if err := yaml.Unmarshal(content, &baseline); err != nil {
+		return Baseline{}, fmt.Errorf("%w: %v", ErrInvalidBaseline, err)
+	}
+
+	if baseline.Version != BaselineVersion {
+		return Baseline{}, fmt.Errorf("%w: unsupported version %d, expected %d", ErrInvalidBaseline, baseline.Version, BaselineVersion)
+	}
+
+	return baseline, nil
+}
+
+// Apply moves the findings known to the baseline out of the reported findings and of the values of their category.
+// The optimized values are left untouched, the baseline only affects what is reported.
+func (b Baseline) Apply(valueStatus ValueStatus) ValueStatus {
+	known := make(map[string]bool, len(b.Findings))
+	for _, entry := range b.Findings {
+		known[entry.Fingerprint] = true
+	}
+
+	valueStatus.Redundant = DeepCopy(valueStatus.Redundant)
+	valueStatus.Unsupported = DeepCopy(valueStatus.Unsupported)
+	valueStatus.Commented = DeepCopy(valueStatus.Commented)
+
+	findings := make([]Finding, 0, len(valueStatus.Findings))
+	for _, finding := range valueStatus.Findings {
+		if !known[finding.Fingerprint()] {
+			findings = append(findings, finding)
+			continue
+		}
+
+		valueStatus.Baselined = append(valueStatus.Baselined, finding)
+		switch finding.Category {
+		case CategoryRedundant:
+			DeleteValue(valueStatus.Redundant, finding.Path)
+		case CategoryUnsupported:
+			DeleteValue(valueStatus.Unsupported, finding.Path)
+		case CategoryCommented:
+			DeleteValue(valueStatus.Commented, finding.Path)
+		}
+	}
+	valueStatus.Findings = findings
+
+	return valueStatus
+}
@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFindingFingerprint(t *testing.T) {
+	base := Finding{File: "values.yaml", Path: "image.tag", Category: CategoryRedundant}
+
+	tests := []struct {
+		name    string
+		finding Finding
+		same    bool
+	}{
+		{
+			name:    "different value",
+			finding: Finding{File: "values.yaml", Path: "image.tag", Category: CategoryRedundant, Value: "2.0", UpstreamValue: "2.0"},
+			same:    true,
+		},
+		{
+			name:    "moved to another line",
+			finding: Finding{File: "values.yaml", Path: "image.tag", Category: CategoryRedundant, Line: 42, Column: 3, UpstreamLine: 7},
+			same:    true,
+		},
+		{
+			name:    "unclean file path",
+			finding: Finding{File: "./config/../values.yaml", Path: "image.tag", Category: CategoryRedundant},
+			same:    true,
+		},
+		{
+			name:    "other file",
+			finding: Finding{File: "prod/values.yaml", Path: "image.tag", Category: CategoryRedundant},
+		},
+		{
+			name:    "other path",
+			finding: Finding{File: "values.yaml", Path: "image.repository", Category: CategoryRedundant},
+		},
+		{
+			name:    "other category",
+			finding: Finding{File: "values.yaml", Path: "image.tag", Category: CategoryTypeMismatch},
+		},
+		{
+			name:    "ambiguous concatenation",
+			finding: Finding{File: "values.yamlimage.tag", Category: CategoryRedundant},
+		},
+	}
+
+	// Committed baselines must keep matching, the fingerprint of a finding never changes across versions
+	if got, want := base.Fingerprint(), "48b313b26ce031b84e184b7abfc68db7"; got != want {
+		t.Fatalf("Fingerprint() = %s, want %s", got, want)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := tt.finding.Fingerprint() == base.Fingerprint(); same != tt.same {
+				t.Errorf("same fingerprint = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+func TestBaselineApply(t *testing.T) {
+	upstream := "image:\n  tag: \"1.0\"\nreplicas: 1\n"
+	recorded := "image:\n  tag: \"1.0\"\nextra: true\n"
+
+	tests := []struct {
+		name          string
+		downstream    string
+		wantFindings  []string
+		wantBaselined []string
+	}{
+		{
+			name:          "unchanged values",
+			downstream:    recorded,
+			wantFindings:  []string{},
+			wantBaselined: []string{"extra=unsupported", "image.tag=redundant"},
+		},
+		{
+			name:          "edited and moved values",
+			downstream:    "# Extra settings\nextra: false\nimage:\n  tag: \"1.0\"\n",
+			wantFindings:  []string{},
+			wantBaselined: []string{"extra=unsupported", "image.tag=redundant"},
+		},
+		{
+			name:          "new finding",
+			downstream:    recorded + "replicas: 1\n",
+			wantFindings:  []string{"replicas=redundant"},
+			wantBaselined: []string{"extra=unsupported", "image.tag=redundant"},
+		},
+		{
+			name:          "fixed finding",
+			downstream:    "image:\n  tag: \"2.0\"\nextra: true\n",
+			wantFindings:  []string{},
+			wantBaselined: []string{"extra=unsupported"},
+		},
+	}
+
+	report, err := Analyze(context.Background(), []byte(upstream), []byte(recorded), WithSourceName("values.yaml"))
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	baseline := NewBaseline(report.Status)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Analyze(context.Background(), []byte(upstream), []byte(tt.downstream),
+				WithSourceName("values.yaml"), WithBaseline(baseline))
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+
+			if got := findingKeys(report.Findings); !reflect.DeepEqual(got, tt.wantFindings) {
+				t.Errorf("findings = %v, want %v", got, tt.wantFindings)
+			}
+			if got := findingKeys(report.Baselined); !reflect.DeepEqual(got, tt.wantBaselined) {
+				t.Errorf("baselined = %v, want %v", got, tt.wantBaselined)
+			}
+		})
+	}
+}
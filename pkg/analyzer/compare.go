@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"sort"
+)
+
+// PathComparison describes a path whose value differs between the compared sources
+type PathComparison struct {
+	Path      string                 `yaml:"path" json:"path"`
+	Values    map[string]interface{} `yaml:"values" json:"values"`
+	MissingIn []string               `yaml:"missingIn,omitempty" json:"missingIn,omitempty"`
+}
+
+// ContextComparison holds the differences of a release's values across kube contexts
+type ContextComparison struct {
+	Release     string           `yaml:"release" json:"release"`
+	Contexts    []string         `yaml:"contexts" json:"contexts"`
+	Differences []PathComparison `yaml:"differences" json:"differences"`
+}
+
+// CompareValueSets compares several named sets of values and returns every leaf path that is
+// either missing from some of the sets or does not hold the same value in all of them
+func CompareValueSets(names []string, sets map[string]map[string]interface{}) []PathComparison {
+	flattened := make(map[string]map[string]interface{}, len(names))
+	allPaths := make(map[string]struct{})
+
+	for _, name := range names {
+		leaves := make(map[string]interface{})
+		flattenValues("", sets[name], leaves)
+		flattened[name] = leaves
+
+		for path := range leaves {
+			allPaths[path] = struct{}{}
+		}
+	}
+
+	paths := make([]string, 0, len(allPaths))
+	for path := range allPaths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	differences := []PathComparison{}
+	for _, path := range paths {
+		comparison := PathComparison{
+			Path:   path,
+			Values: make(map[string]interface{}),
+		}
+
+		differs := false
+		var reference interface{}
+		hasReference := false
+
+		for _, name := range names {
+			value, exists := flattened[name][path]
+			if !exists {
+				comparison.MissingIn = append(comparison.MissingIn, name)
+				differs = true
+				continue
+			}
+
+			comparison.Values[name] = value
+			if !hasReference {
+				reference = value
+				hasReference = true
+			} else if !equalValues(reference, value) {
+				differs = true
+			}
+		}
+
+		if differs {
+			differences = append(differences, comparison)
+		}
+	}
+
+	return differences
+}
+
+// flattenValues collects the leaf values of a nested map keyed by their dot-notation path
+func flattenValues(path string, values map[string]interface{}, leaves map[string]interface{}) {
+	for key, value := range values {
+		currentPath := joinPath(path, key)
+
+		if nestedMap, isMap := value.(map[string]interface{}); isMap && len(nestedMap) > 0 {
+			flattenValues(currentPath, nestedMap, leaves)
+			continue
+		}
+
+		leaves[currentPath] = value
+	}
+}
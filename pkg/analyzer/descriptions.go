@@ -0,0 +1,429 @@
+package analyzer
+
+import (
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// helmDocsPrefix marks the start of a key description in the helm-docs comment convention
+const helmDocsPrefix = "# --"
+
+// ParseDescriptions extracts the helm-docs style "# --" description of every key in a values file
+func ParseDescriptions(content []byte) (map[string]string, error) {
+	descriptions := make(map[string]string)
+
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+
+	if len(document.Content) > 0 {
+		collectDescriptions("", document.Content[0], descriptions)
+	}
+
+	return descriptions, nil
+}
+
+// collectDescriptions walks a mapping node and records the description found above each key
+func collectDescriptions(path string, node *yamlv3.Node, descriptions map[string]string) {
+	if node.Kind != yamlv3.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if keyNode.Tag == "!!merge" {
+			continue
+		}
+
+		currentPath := joinPath(path, keyNode.Value)
+		if description := helmDocsDescription(keyNode.HeadComment); description != "" {
+			descriptions[currentPath] = description
+		}
+
+		collectDescriptions(currentPath, valueNode, descriptions)
+	}
+}
+
+// helmDocsDescription returns the "# --" description of a comment block, joined into a single line.
+// The description continues on the following comment lines until an "# @" annotation or the end of the block.
+func helmDocsDescription(comment string) string {
+	var parts []string
+	inDescription := false
+
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, helmDocsPrefix) {
+			// A later description replaces an earlier one, it is the one closest to the key
+			parts = []string{strings.TrimSpace(strings.TrimPrefix(line, helmDocsPrefix))}
+			inDescription = true
+			continue
+		}
+
+		if !inDescription {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "#") || strings.HasPrefix(line, "# @") {
+			inDescription = false
+			continue
+		}
+
+		parts = append(parts, strings.TrimSpace(strings.TrimLeft(line, "#")))
+	}
+
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+// KeyDoc documents a key of a values file
+type KeyDoc struct {
+	Path    string      `yaml:"path" json:"path"`
+	Type    string      `yaml:"type" json:"type"`
+	Default interface{} `yaml:"default" json:"default"`
+	Line    int         `yaml:"line,omitempty" json:"line,omitempty"`
+	// Description is the "# --" or "##" comment block above the key, joined into a single line
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Example is a commented-out example of the value, uncommented
+	Example string `yaml:"example,omitempty" json:"example,omitempty"`
+	// Commented is set for keys that only exist as a commented-out example
+	Commented bool `yaml:"commented,omitempty" json:"commented,omitempty"`
+}
+
+// ParseKeyDocs documents every key of a values file in document order, parents before their children
+func ParseKeyDocs(content []byte) ([]KeyDoc, error) {
+	values, _, err := ParseValues(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &document); err != nil {
+		return nil, &ValuesError{Err: err}
+	}
+
+	var docs []KeyDoc
+	if len(document.Content) > 0 {
+		lines := strings.Split(string(content), "\n")
+		collectKeyDocs("", resolveAlias(document.Content[0]), 0, values, lines, paramDescriptions(lines), &docs)
+	}
+
+	return docs, nil
+}
+
+// collectKeyDocs walks a mapping node and documents each of its keys, and the keys commented out between them.
+// The mapping belongs to the key on line parentLine, or is the document when parentLine is 0.
+func collectKeyDocs(path string, node *yamlv3.Node, parentLine int, values map[string]interface{}, lines []string, params map[string]string, docs *[]KeyDoc) {
+	if node.Kind != yamlv3.MappingNode {
+		return
+	}
+
+	commented := commentedKeyDocs(path, node, parentLine, lines, params)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if keyNode.Tag == "!!merge" {
+			continue
+		}
+
+		for len(commented) > 0 && commented[0].Line < keyNode.Line {
+			*docs = append(*docs, commented[0])
+			commented = commented[1:]
+		}
+
+		currentPath := joinPath(path, keyNode.Value)
+		value, _ := LookupValue(values, currentPath)
+		doc := KeyDoc{
+			Path:        currentPath,
+			Type:        ValueKind(value),
+			Default:     value,
+			Line:        keyNode.Line,
+			Description: keyDescription(currentPath, keyNode.HeadComment, params),
+		}
+		if isEmptyNode(valueNode) {
+			doc.Example = commentedExample(lines, keyNode.Line, keyNode.Column-1)
+		}
+		*docs = append(*docs, doc)
+
+		collectKeyDocs(currentPath, resolveAlias(valueNode), keyNode.Line, values, lines, params, docs)
+	}
+
+	*docs = append(*docs, commented...)
+}
+
+// commentedKeyDocs documents the keys commented out at the indentation of the keys of a block mapping,
+// in the order of the file. Keys the mapping defines are skipped, their comments are examples of the value.
+func commentedKeyDocs(path string, node *yamlv3.Node, parentLine int, lines []string, params map[string]string) []KeyDoc {
+	if node.Style&yamlv3.FlowStyle != 0 || len(node.Content) == 0 {
+		return nil
+	}
+
+	keyIndent := node.Content[0].Column - 1
+	defined := make(map[string]bool, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		defined[node.Content[i].Value] = true
+	}
+
+	var docs []KeyDoc
+	for i := parentLine; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" {
+			continue
+		}
+		if indentation(lines[i]) < keyIndent {
+			if strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			break
+		}
+		if indentation(lines[i]) != keyIndent || !strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		name, ok := commentedKeyName(uncomment(lines[i]))
+		if !ok || defined[name] {
+			continue
+		}
+		// A key commented out more than once is documented by its first example
+		defined[name] = true
+		docs = append(docs, commentedKeyDoc(lines, i, joinPath(path, name), params))
+	}
+
+	return docs
+}
+
+// commentedKeyName returns the key of an uncommented line written as "key: value" or "key:", telling
+// commented-out keys apart from prose such as "Note: ..." or "ref: https://..."
+func commentedKeyName(line string) (string, bool) {
+	if line != strings.TrimLeft(line, " ") || !looksLikeYAML(line) || strings.HasPrefix(line, "- ") {
+		return "", false
+	}
+
+	key, value, _ := strings.Cut(line, ":")
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "", strings.ContainsAny(value[:1], "\"'[{|>&*"):
+	case strings.Contains(value, "://"), strings.ContainsAny(value, " \t"):
+		return "", false
+	}
+	return key, true
+}
+
+// FindCommentedKey documents a key that only exists as a commented-out example below its parent key,
+// returning false when no such example is found
+func FindCommentedKey(content []byte, path string) (KeyDoc, bool) {
+	lines := strings.Split(string(content), "\n")
+	parts := strings.Split(path, ".")
+	name := parts[len(parts)-1]
+
+	// The example is searched within the block of the closest parent present in the file
+	start, parentIndent := 0, -1
+	if len(parts) > 1 {
+		_, positions, err := ParseValues(content)
+		if err != nil {
+			return KeyDoc{}, false
+		}
+		position, ok := positions[strings.Join(parts[:len(parts)-1], ".")]
+		if !ok {
+			return KeyDoc{}, false
+		}
+		start, parentIndent = position.Line, position.Column-1
+	}
+
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" {
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "#") {
+			if indentation(lines[i]) <= parentIndent {
+				break
+			}
+			continue
+		}
+
+		// Top level keys are commented out at the start of the line
+		if parentIndent < 0 && indentation(lines[i]) > 0 {
+			continue
+		}
+
+		uncommented := uncomment(lines[i])
+		key, _, found := strings.Cut(strings.TrimSpace(uncommented), ":")
+		if !found || strings.TrimSpace(key) != name {
+			continue
+		}
+
+		return commentedKeyDoc(lines, i, path, paramDescriptions(lines)), true
+	}
+
+	return KeyDoc{}, false
+}
+
+// commentedKeyDoc documents the key commented out on a line. The example is the commented line and the
+// more indented commented lines that follow it.
+func commentedKeyDoc(lines []string, index int, path string, params map[string]string) KeyDoc {
+	uncommented := uncomment(lines[index])
+	example := []string{strings.TrimSpace(uncommented)}
+	keyIndent := indentation(uncommented)
+	for _, line := range lines[index+1:] {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			break
+		}
+		next := uncomment(line)
+		if strings.TrimSpace(next) == "" || indentation(next) <= keyIndent {
+			break
+		}
+		example = append(example, strings.TrimRight(next[keyIndent:], " "))
+	}
+
+	return KeyDoc{
+		Path:        path,
+		Type:        KindNull,
+		Line:        index + 1,
+		Description: keyDescription(path, strings.Join(precedingComments(lines, index), "\n"), params),
+		Example:     strings.Join(example, "\n"),
+		Commented:   true,
+	}
+}
+
+// paramDescriptions collects the "## @param <path> <description>" lines of a values file by path, as written
+// by the Bitnami readme generator which lists the parameters of a whole section above its first key.
+// Modifiers such as [array] or [default: value] before the description are dropped.
+func paramDescriptions(lines []string) map[string]string {
+	params := make(map[string]string)
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(strings.TrimSpace(strings.TrimLeft(line, "#")), " ", 3)
+		if len(fields) < 3 || fields[0] != "@param" {
+			continue
+		}
+
+		description := strings.TrimSpace(fields[2])
+		for strings.HasPrefix(description, "[") {
+			end := strings.Index(description, "]")
+			if end < 0 {
+				break
+			}
+			description = strings.TrimSpace(description[end+1:])
+		}
+		if description != "" {
+			params[fields[1]] = description
+		}
+	}
+	return params
+}
+
+// keyDescription returns the "## @param" description of a path, or the description of the comment above the key
+func keyDescription(path, comment string, params map[string]string) string {
+	if description, ok := params[path]; ok {
+		return description
+	}
+	return docDescription(comment)
+}
+
+// docDescription returns the description of a comment block, preferring the helm-docs "# --" convention
+// over the "##" comments used by many charts. "## @" annotations are not part of the description, "## @param"
+// lines describe the path they name rather than the key below them.
+func docDescription(comment string) string {
+	if description := helmDocsDescription(comment); description != "" {
+		return description
+	}
+
+	var parts []string
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "##") {
+			// Only the block closest to the key describes it
+			parts = nil
+			continue
+		}
+
+		line = strings.TrimSpace(strings.TrimLeft(line, "#"))
+		if strings.HasPrefix(line, "@") || line == "" {
+			continue
+		}
+		parts = append(parts, line)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// commentedExample returns the commented-out YAML directly below a key holding an empty value,
+// as written in many charts for maps like resources or annotations
+func commentedExample(lines []string, keyLine, keyIndent int) string {
+	var example []string
+	baseIndent := -1
+	for _, line := range lines[keyLine:] {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") || indentation(line) <= keyIndent {
+			break
+		}
+
+		uncommented := uncomment(line)
+		if strings.TrimSpace(uncommented) == "" {
+			break
+		}
+		if baseIndent < 0 {
+			if !looksLikeYAML(uncommented) {
+				return ""
+			}
+			baseIndent = indentation(uncommented)
+		}
+		if indentation(uncommented) < baseIndent {
+			break
+		}
+		example = append(example, strings.TrimRight(uncommented[baseIndent:], " "))
+	}
+
+	return strings.Join(example, "\n")
+}
+
+// precedingComments returns the comment lines directly above a line
+func precedingComments(lines []string, index int) []string {
+	start := index
+	for start > 0 && strings.HasPrefix(strings.TrimSpace(lines[start-1]), "#") {
+		start--
+	}
+	comments := make([]string, 0, index-start)
+	for _, line := range lines[start:index] {
+		comments = append(comments, strings.TrimSpace(line))
+	}
+	return comments
+}
+
+// uncomment removes the comment marker of a line and the space following it, keeping the indentation of the content
+func uncomment(line string) string {
+	trimmed := strings.TrimSpace(line)
+	trimmed = strings.TrimPrefix(trimmed, "#")
+	return strings.TrimPrefix(trimmed, " ")
+}
+
+// looksLikeYAML reports whether an uncommented line is a YAML key or list item rather than prose
+func looksLikeYAML(line string) bool {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "- ") {
+		return true
+	}
+	key, _, found := strings.Cut(line, ":")
+	return found && key != "" && !strings.ContainsAny(key, " \t")
+}
+
+// indentation returns the number of leading spaces of a line
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// isEmptyNode reports whether a value node is null or an empty map or list
+func isEmptyNode(node *yamlv3.Node) bool {
+	switch node.Kind {
+	case yamlv3.ScalarNode:
+		return node.Tag == "!!null"
+	case yamlv3.MappingNode, yamlv3.SequenceNode:
+		return len(node.Content) == 0
+	default:
+		return false
+	}
+}
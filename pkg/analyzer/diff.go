@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"sort"
+)
+
+// Change types reported by DiffValues
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// ValueChange describes a single value that differs between two sets of values
+type ValueChange struct {
+	Path     string      `yaml:"path" json:"path"`
+	Type     string      `yaml:"type" json:"type"`
+	OldValue interface{} `yaml:"oldValue,omitempty" json:"oldValue,omitempty"`
+	NewValue interface{} `yaml:"newValue,omitempty" json:"newValue,omitempty"`
+}
+
+// RevisionChanges holds the value changes introduced by a single release revision
+type RevisionChanges struct {
+	Revision    int           `yaml:"revision" json:"revision"`
+	Updated     string        `yaml:"updated,omitempty" json:"updated,omitempty"`
+	Status      string        `yaml:"status,omitempty" json:"status,omitempty"`
+	Chart       string        `yaml:"chart,omitempty" json:"chart,omitempty"`
+	AppVersion  string        `yaml:"appVersion,omitempty" json:"appVersion,omitempty"`
+	Description string        `yaml:"description,omitempty" json:"description,omitempty"`
+	Changes     []ValueChange `yaml:"changes" json:"changes"`
+}
+
+// Timeline is the per-revision history of user supplied values for a release
+type Timeline struct {
+	Release   string            `yaml:"release" json:"release"`
+	Revisions []RevisionChanges `yaml:"revisions" json:"revisions"`
+}
+
+// DiffValues compares two sets of values and returns every added, removed or changed path, sorted by path
+func DiffValues(previous, current map[string]interface{}) []ValueChange {
+	changes := []ValueChange{}
+	diffValues("", previous, current, &changes)
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+// diffValues recursively collects the differences between two nested maps
+func diffValues(path string, previous, current map[string]interface{}, changes *[]ValueChange) {
+	for key, oldVal := range previous {
+		currentPath := joinPath(path, key)
+
+		newVal, exists := current[key]
+		if !exists {
+			*changes = append(*changes, ValueChange{Path: currentPath, Type: ChangeRemoved, OldValue: oldVal})
+			continue
+		}
+
+		// If both are maps, recurse so that only the leaves that differ are reported
+		oldMap, oldIsMap := oldVal.(map[string]interface{})
+		newMap, newIsMap := newVal.(map[string]interface{})
+		if oldIsMap && newIsMap {
+			diffValues(currentPath, oldMap, newMap, changes)
+			continue
+		}
+
+		if !equalValues(oldVal, newVal) {
+			*changes = append(*changes, ValueChange{Path: currentPath, Type: ChangeChanged, OldValue: oldVal, NewValue: newVal})
+		}
+	}
+
+	for key, newVal := range current {
+		if _, exists := previous[key]; !exists {
+			*changes = append(*changes, ValueChange{Path: joinPath(path, key), Type: ChangeAdded, NewValue: newVal})
+		}
+	}
+}
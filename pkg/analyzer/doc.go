@@ -0,0 +1,18 @@
+// Package analyzer compares the values of a Helm release or values file with the defaults of its chart.
+//
+// Analyze is the entry point for programs embedding the analysis:
+//
+//	report, err := analyzer.Analyze(ctx, upstreamYAML, downstreamYAML,
+//		analyzer.WithSourceName("values.yaml"),
+//		analyzer.WithIgnoreRules(rule),
+//		analyzer.WithPolicy(analyzer.Policy{FailOnUnsupported: true, MaxRedundant: -1}),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	for _, finding := range report.Findings {
+//		fmt.Printf("%s:%d: %s is %s\n", finding.File, finding.Line, finding.Path, finding.Category)
+//	}
+//
+// Analyze neither logs nor writes files. The Analyzer type is the lower-level building block it is based on.
+package analyzer
@@ -0,0 +1,177 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// DuplicateKey is a mapping key defined more than once, only the last definition is kept when the file is read
+type DuplicateKey struct {
+	Path string `yaml:"path" json:"path"`
+	// Lines lists the line of every definition in file order
+	Lines []int `yaml:"lines" json:"lines"`
+}
+
+// DuplicateKeysError reports the duplicate keys of a values file
+type DuplicateKeysError struct {
+	File       string
+	Duplicates []DuplicateKey
+}
+
+func (e *DuplicateKeysError) Error() string {
+	parts := make([]string, 0, len(e.Duplicates))
+	for _, duplicate := range e.Duplicates {
+		lines := make([]string, 0, len(duplicate.Lines))
+		for _, line := range duplicate.Lines {
+			lines = append(lines, fmt.Sprint(line))
+		}
+		parts = append(parts, fmt.Sprintf("%s (lines %s)", duplicate.Path, strings.Join(lines, ", ")))
+	}
+
+	message := "duplicate keys"
+	if e.File != "" {
+		message += " in " + e.File
+	}
+	return message + ": " + strings.Join(parts, ", ")
+}
+
+// Is makes every DuplicateKeysError match ErrDuplicateKeys
+func (e *DuplicateKeysError) Is(target error) bool {
+	return target == ErrDuplicateKeys
+}
+
+// FindDuplicateKeys returns the keys defined more than once in the same mapping, at any depth, sorted by line.
+// Items of lists are addressed by their index, e.g. containers[0].name.
+func FindDuplicateKeys(content []byte) ([]DuplicateKey, error) {
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &document); err != nil {
+		return nil, &ValuesError{Err: err}
+	}
+
+	var duplicates []DuplicateKey
+	if len(document.Content) > 0 {
+		collectDuplicateKeys("", document.Content[0], &duplicates)
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].Lines[0] < duplicates[j].Lines[0]
+	})
+
+	return duplicates, nil
+}
+
+// collectDuplicateKeys walks a node and records the keys its mappings define more than once
+func collectDuplicateKeys(path string, node *yamlv3.Node, duplicates *[]DuplicateKey) {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		lines := make(map[string][]int)
+		var order []string
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			if keyNode.Tag == "!!merge" {
+				continue
+			}
+			if _, seen := lines[keyNode.Value]; !seen {
+				order = append(order, keyNode.Value)
+			}
+			lines[keyNode.Value] = append(lines[keyNode.Value], keyNode.Line)
+		}
+		for _, key := range order {
+			if len(lines[key]) > 1 {
+				*duplicates = append(*duplicates, DuplicateKey{Path: joinPath(path, key), Lines: lines[key]})
+			}
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Tag != "!!merge" {
+				collectDuplicateKeys(joinPath(path, node.Content[i].Value), node.Content[i+1], duplicates)
+			}
+		}
+	case yamlv3.SequenceNode:
+		for i, item := range node.Content {
+			collectDuplicateKeys(fmt.Sprintf("%s[%d]", path, i), item, duplicates)
+		}
+	}
+}
+
+// MergeDuplicateKeys rewrites a values file with each duplicate key defined once, at its first position.
+// Mappings defined several times are merged, the later definitions taking precedence, and other values keep
+// their last definition as Helm does. Comments are kept, the file is written with the indentation of FormatValues.
+func MergeDuplicateKeys(content []byte) ([]byte, error) {
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &document); err != nil {
+		return nil, &ValuesError{Err: err}
+	}
+	if len(document.Content) == 0 {
+		return content, nil
+	}
+
+	mergeDuplicateKeys(document.Content[0])
+
+	var b bytes.Buffer
+	encoder := yamlv3.NewEncoder(&b)
+	encoder.SetIndent(FormatIndent)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, fmt.Errorf("failed to encode values: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode values: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// mergeDuplicateKeys merges the duplicate keys of the mappings of a node, children first
+func mergeDuplicateKeys(node *yamlv3.Node) {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		merged := make([]*yamlv3.Node, 0, len(node.Content))
+		index := make(map[string]int)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			first, seen := index[keyNode.Value]
+			if keyNode.Tag == "!!merge" || !seen {
+				if keyNode.Tag != "!!merge" {
+					index[keyNode.Value] = len(merged)
+				}
+				merged = append(merged, keyNode, valueNode)
+				continue
+			}
+
+			firstKey, firstValue := merged[first], merged[first+1]
+			firstKey.HeadComment = joinComments(firstKey.HeadComment, keyNode.HeadComment)
+			firstKey.LineComment = joinComments(firstKey.LineComment, keyNode.LineComment)
+			if firstValue.Kind == yamlv3.MappingNode && valueNode.Kind == yamlv3.MappingNode {
+				firstValue.Content = append(firstValue.Content, valueNode.Content...)
+				firstValue.FootComment = joinComments(firstValue.FootComment, valueNode.FootComment)
+			} else {
+				merged[first+1] = valueNode
+			}
+		}
+		node.Content = merged
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			mergeDuplicateKeys(node.Content[i+1])
+		}
+	case yamlv3.SequenceNode:
+		for _, item := range node.Content {
+			mergeDuplicateKeys(item)
+		}
+	}
+}
+
+// joinComments concatenates two comment blocks
+func joinComments(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	default:
+		return a + "\n" + b
+	}
+}
@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		wantDuplicates []DuplicateKey
+		want           string
+	}{
+		{
+			name:    "no duplicates",
+			content: "image:\n  tag: \"1.0\"\nreplicas: 2\n",
+			want:    "image:\n  tag: \"1.0\"\nreplicas: 2\n",
+		},
+		{
+			name:           "mappings are merged at the first definition",
+			content:        "image:\n  tag: \"1.0\"\nreplicas: 2\nimage:\n  pullPolicy: Always\n",
+			wantDuplicates: []DuplicateKey{{Path: "image", Lines: []int{1, 4}}},
+			want:           "image:\n  tag: \"1.0\"\n  pullPolicy: Always\nreplicas: 2\n",
+		},
+		{
+			name:           "later definitions take precedence",
+			content:        "image:\n  tag: \"1.0\"\nimage:\n  tag: \"2.0\"\n",
+			wantDuplicates: []DuplicateKey{{Path: "image", Lines: []int{1, 3}}},
+			want:           "image:\n  tag: \"2.0\"\n",
+		},
+		{
+			name:           "scalars keep their last definition",
+			content:        "replicas: 1\nname: web\nreplicas: 3\n",
+			wantDuplicates: []DuplicateKey{{Path: "replicas", Lines: []int{1, 3}}},
+			want:           "replicas: 3\nname: web\n",
+		},
+		{
+			name:           "nested and list items",
+			content:        "containers:\n  - name: web\n    name: api\nservice:\n  port: 80\n  port: 8080\n",
+			wantDuplicates: []DuplicateKey{{Path: "containers[0].name", Lines: []int{2, 3}}, {Path: "service.port", Lines: []int{5, 6}}},
+			want:           "containers:\n  - name: api\nservice:\n  port: 8080\n",
+		},
+		{
+			name:           "comments are kept",
+			content:        "# Image\nimage:\n  tag: \"1.0\" # pinned\n# Pull policy\nimage:\n  pullPolicy: Always\n",
+			wantDuplicates: []DuplicateKey{{Path: "image", Lines: []int{2, 5}}},
+			want:           "# Image\n# Pull policy\nimage:\n  tag: \"1.0\" # pinned\n  pullPolicy: Always\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duplicates, err := FindDuplicateKeys([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("FindDuplicateKeys() error = %v", err)
+			}
+			if !reflect.DeepEqual(duplicates, tt.wantDuplicates) {
+				t.Errorf("duplicates = %v, want %v", duplicates, tt.wantDuplicates)
+			}
+
+			merged, err := MergeDuplicateKeys([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("MergeDuplicateKeys() error = %v", err)
+			}
+			if string(merged) != tt.want {
+				t.Errorf("merged =\n%s\nwant\n%s", merged, tt.want)
+			}
+
+			if duplicates, err := FindDuplicateKeys(merged); err != nil || len(duplicates) != 0 {
+				t.Errorf("merged values still define duplicate keys: %v, %v", duplicates, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of invalid input, test for them with errors.Is
+var (
+	ErrInvalidValues     = errors.New("invalid values")
+	ErrInvalidIgnoreRule = errors.New("invalid ignore rule")
+	ErrInvalidAnnotation = errors.New("invalid annotation")
+	ErrInvalidBaseline   = errors.New("invalid baseline")
+	ErrDuplicateKeys     = errors.New("duplicate keys")
+	ErrInvalidSchema     = errors.New("invalid schema")
+)
+
+// ValuesError reports malformed values YAML, with the line of the problem when it is known
+type ValuesError struct {
+	Line int
+	Err  error
+}
+
+func (e *ValuesError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ValuesError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes every ValuesError match ErrInvalidValues
+func (e *ValuesError) Is(target error) bool {
+	return target == ErrInvalidValues
+}
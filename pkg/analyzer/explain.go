@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"strings"
+)
+
+// Explanation describes an upstream key and how the downstream values set it
+type Explanation struct {
+	KeyDoc `yaml:",inline"`
+	// Upstream reports whether the key is defined by the upstream values, rather than only commented out or absent
+	Upstream bool `yaml:"upstream" json:"upstream"`
+	// Downstream is the downstream value of the key, set when DownstreamSet is
+	Downstream     interface{} `yaml:"downstream,omitempty" json:"downstream,omitempty"`
+	DownstreamSet  bool        `yaml:"downstreamSet" json:"downstreamSet"`
+	DownstreamFile string      `yaml:"downstreamFile,omitempty" json:"downstreamFile,omitempty"`
+	DownstreamLine int         `yaml:"downstreamLine,omitempty" json:"downstreamLine,omitempty"`
+	// Findings lists the findings of the downstream values on the key, its parents or its children
+	Findings []Finding `yaml:"findings,omitempty" json:"findings,omitempty"`
+}
+
+// Explain describes a key of the upstream values: its default, type, documentation and commented-out example,
+// and how the downstream values override it. The documentation requires OriginalUpstreamYAML.
+func (a *Analyzer) Explain(path string) Explanation {
+	if a.downstreamValues == nil {
+		return a.explain(path, nil)
+	}
+	status := a.Analyze()
+	return a.explain(path, &status)
+}
+
+// explain describes a key with the findings of an analysis, or without the downstream values when status is nil
+func (a *Analyzer) explain(path string, status *ValueStatus) Explanation {
+	explanation := Explanation{KeyDoc: KeyDoc{Path: path, Type: KindNull}}
+
+	if value, ok := LookupValue(a.upstreamValues, path); ok {
+		explanation.Upstream = true
+		explanation.Type = ValueKind(value)
+		explanation.Default = value
+		if position, ok := a.UpstreamPositions[path]; ok {
+			explanation.Line = position.Line
+		}
+	}
+
+	if a.OriginalUpstreamYAML != nil {
+		if explanation.Upstream {
+			if docs, err := ParseKeyDocs(a.OriginalUpstreamYAML); err == nil {
+				for _, doc := range docs {
+					if doc.Path == path {
+						explanation.Line = doc.Line
+						explanation.Description = doc.Description
+						explanation.Example = doc.Example
+						break
+					}
+				}
+			}
+		} else if doc, ok := FindCommentedKey(a.OriginalUpstreamYAML, path); ok {
+			explanation.KeyDoc = doc
+		}
+	}
+
+	if status == nil {
+		return explanation
+	}
+
+	explanation.DownstreamFile = a.DownstreamFile
+	if value, ok := LookupValue(a.downstreamValues, path); ok {
+		explanation.Downstream = value
+		explanation.DownstreamSet = true
+		if position, ok := a.DownstreamPositions[path]; ok {
+			explanation.DownstreamLine = position.Line
+		}
+	}
+
+	for _, finding := range status.Findings {
+		if finding.Path == path || strings.HasPrefix(finding.Path, path+".") || strings.HasPrefix(path, finding.Path+".") {
+			explanation.Findings = append(explanation.Findings, finding)
+		}
+	}
+
+	return explanation
+}
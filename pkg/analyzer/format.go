@@ -0,0 +1,206 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// FormatIndent is the indentation of formatted values files
+const FormatIndent = 2
+
+// FormatValues rewrites a values file in a canonical form. Keys follow the order of the upstream values, keys
+// the upstream values do not define come after them in alphabetical order. Keys of list items are only reordered
+// when the upstream list holds a mapping to follow. Indentation is normalized and strings are only quoted, with
+// double quotes, when they would otherwise be read as another value. Comments, anchors and aliases are preserved.
+// Without upstream values every mapping is sorted alphabetically.
+func FormatValues(content, upstream []byte) ([]byte, error) {
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &document); err != nil {
+		return nil, &ValuesError{Err: err}
+	}
+	if len(document.Content) == 0 {
+		return content, nil
+	}
+
+	var upstreamRoot *yamlv3.Node
+	if upstream != nil {
+		var upstreamDocument yamlv3.Node
+		if err := yamlv3.Unmarshal(upstream, &upstreamDocument); err != nil {
+			return nil, fmt.Errorf("failed to parse upstream values: %w", &ValuesError{Err: err})
+		}
+		if len(upstreamDocument.Content) > 0 {
+			upstreamRoot = upstreamDocument.Content[0]
+		}
+	}
+
+	formatNode(document.Content[0], upstreamRoot, true)
+
+	var b bytes.Buffer
+	encoder := yamlv3.NewEncoder(&b)
+	encoder.SetIndent(FormatIndent)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, fmt.Errorf("failed to encode values: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode values: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// formatNode normalizes a node and its children. The upstream node, when known, gives the order of mapping keys,
+// keys it does not define are sorted when sortKeys is set and keep their order otherwise.
+func formatNode(node, upstream *yamlv3.Node, sortKeys bool) {
+	if upstream != nil {
+		upstream = resolveAlias(upstream)
+	}
+
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		// Non-empty collections are written in block style
+		if len(node.Content) > 0 {
+			node.Style = 0
+		}
+		if upstream != nil && upstream.Kind != yamlv3.MappingNode {
+			upstream = nil
+		}
+		orderMapping(node, upstream, sortKeys)
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			if keyNode.Tag == "!!merge" {
+				// yaml.v3 writes merge keys as "!!merge <<" unless their tag is cleared
+				keyNode.Tag = ""
+				continue
+			}
+			formatScalar(keyNode)
+
+			upstreamValue := mappingValue(upstream, keyNode.Value)
+			formatNode(valueNode, upstreamValue, upstreamValue != nil || sortKeys)
+		}
+	case yamlv3.SequenceNode:
+		if len(node.Content) > 0 {
+			node.Style = 0
+		}
+
+		// List items follow the first mapping of the upstream list, if any
+		var template *yamlv3.Node
+		if upstream != nil && upstream.Kind == yamlv3.SequenceNode {
+			for _, item := range upstream.Content {
+				if item = resolveAlias(item); item.Kind == yamlv3.MappingNode {
+					template = item
+					break
+				}
+			}
+		}
+		for _, item := range node.Content {
+			formatNode(item, template, template != nil)
+		}
+	case yamlv3.ScalarNode:
+		formatScalar(node)
+	}
+}
+
+// orderMapping sorts the key and value pairs of a mapping: merge keys first, then the keys of the upstream
+// mapping in its order, then the remaining keys alphabetically when sortKeys is set
+func orderMapping(node, upstream *yamlv3.Node, sortKeys bool) {
+	upstreamIndex := make(map[string]int)
+	if upstream != nil {
+		for i := 0; i+1 < len(upstream.Content); i += 2 {
+			if _, exists := upstreamIndex[upstream.Content[i].Value]; !exists {
+				upstreamIndex[upstream.Content[i].Value] = i
+			}
+		}
+	}
+
+	type pair struct {
+		key, value *yamlv3.Node
+	}
+	pairs := make([]pair, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, pair{node.Content[i], node.Content[i+1]})
+	}
+
+	// rank groups merge keys, upstream keys and other keys, in that order
+	rank := func(p pair) int {
+		if p.key.Tag == "!!merge" {
+			return 0
+		}
+		if _, ok := upstreamIndex[p.key.Value]; ok {
+			return 1
+		}
+		return 2
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		ri, rj := rank(pairs[i]), rank(pairs[j])
+		if ri != rj {
+			return ri < rj
+		}
+		switch ri {
+		case 1:
+			return upstreamIndex[pairs[i].key.Value] < upstreamIndex[pairs[j].key.Value]
+		case 2:
+			return sortKeys && pairs[i].key.Value < pairs[j].key.Value
+		default:
+			return false
+		}
+	})
+
+	node.Content = node.Content[:0]
+	for _, p := range pairs {
+		node.Content = append(node.Content, p.key, p.value)
+	}
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil
+func mappingValue(mapping *yamlv3.Node, key string) *yamlv3.Node {
+	if mapping == nil {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// formatScalar drops the quotes of strings that read the same without them and double quotes the others.
+// Block scalars and values of other types are left as written.
+func formatScalar(node *yamlv3.Node) {
+	if node.Tag != "!!str" || (node.Style != yamlv3.SingleQuotedStyle && node.Style != yamlv3.DoubleQuotedStyle) {
+		return
+	}
+
+	if needsQuotes(node.Value) {
+		node.Style = yamlv3.DoubleQuotedStyle
+	} else {
+		node.Style = 0
+	}
+}
+
+// needsQuotes reports whether a string would be read as another value when written without quotes.
+// Helm reads values with YAML 1.1 rules, so both yaml.v2 and yaml.v3 must agree on the plain value.
+func needsQuotes(value string) bool {
+	if value == "" || strings.TrimSpace(value) != value || strings.Contains(value, "\n") {
+		return true
+	}
+
+	var v2 map[string]interface{}
+	if err := yaml.Unmarshal([]byte("v: "+value), &v2); err != nil || v2["v"] != value {
+		return true
+	}
+
+	var v3 map[string]interface{}
+	if err := yamlv3.Unmarshal([]byte("v: "+value), &v3); err != nil || v3["v"] != value {
+		return true
+	}
+
+	return false
+}
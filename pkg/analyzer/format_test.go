@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+const formatUpstream = `image:
+  repository: nginx
+  tag: "1.0"
+replicas: 1
+service:
+  type: ClusterIP
+  port: 80
+`
+
+func TestFormatValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		upstream string
+		want     string
+	}{
+		{
+			name:     "keys follow the upstream order",
+			content:  "service:\n  port: 8080\n  type: NodePort\nreplicas: 2\nimage:\n  tag: \"2.0\"\n  repository: custom\n",
+			upstream: formatUpstream,
+			want:     "image:\n  repository: custom\n  tag: \"2.0\"\nreplicas: 2\nservice:\n  type: NodePort\n  port: 8080\n",
+		},
+		{
+			name:     "unknown keys are sorted after upstream keys",
+			content:  "zeta: 1\nreplicas: 2\nalpha: 1\n",
+			upstream: formatUpstream,
+			want:     "replicas: 2\nalpha: 1\nzeta: 1\n",
+		},
+		{
+			name:    "without upstream keys are sorted",
+			content: "service:\n  type: NodePort\n  port: 8080\nimage: nginx\n",
+			want:    "image: nginx\nservice:\n  port: 8080\n  type: NodePort\n",
+		},
+		{
+			name:     "quotes are only kept when needed",
+			content:  "image:\n  repository: 'nginx'\n  tag: '1.0'\nservice:\n  type: \"yes\"\n",
+			upstream: formatUpstream,
+			want:     "image:\n  repository: nginx\n  tag: \"1.0\"\nservice:\n  type: \"yes\"\n",
+		},
+		{
+			name:     "indentation is normalized",
+			content:  "image:\n    tag: \"2.0\"\nlist:\n- a\n- b\n",
+			upstream: formatUpstream,
+			want:     "image:\n  tag: \"2.0\"\nlist:\n  - a\n  - b\n",
+		},
+		{
+			name:     "comments and anchors are kept",
+			content:  "# Service\nservice: &service\n  port: 8080 # custom\nother: *service\n",
+			upstream: formatUpstream,
+			want:     "# Service\nservice: &service\n  port: 8080 # custom\nother: *service\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var upstream []byte
+			if tt.upstream != "" {
+				upstream = []byte(tt.upstream)
+			}
+
+			formatted, err := FormatValues([]byte(tt.content), upstream)
+			if err != nil {
+				t.Fatalf("FormatValues() error = %v", err)
+			}
+			if string(formatted) != tt.want {
+				t.Errorf("formatted =\n%s\nwant\n%s", formatted, tt.want)
+			}
+
+			again, err := FormatValues(formatted, upstream)
+			if err != nil {
+				t.Fatalf("FormatValues() of formatted values error = %v", err)
+			}
+			if string(again) != string(formatted) {
+				t.Errorf("formatting is not idempotent, second pass =\n%s\nfirst pass\n%s", again, formatted)
+			}
+		})
+	}
+}
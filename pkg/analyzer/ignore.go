@@ -0,0 +1,212 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Inline annotations recognized in the comments of a downstream values file
+const (
+	// AnnotationKeep keeps a key and everything below it, suppressing all of its findings
+	AnnotationKeep = "hvm:keep"
+	// AnnotationIgnore suppresses the listed finding categories for a key and everything below it
+	AnnotationIgnore = "hvm:ignore"
+)
+
+// IgnoreRule suppresses the findings of the paths matching a pattern, and of everything below them
+type IgnoreRule struct {
+	// Pattern is a dot-separated path where * matches one key and ** any number of keys
+	Pattern string
+	// Categories limits the rule to some finding categories, the rule applies to all of them when empty
+	Categories []Category
+	// Source describes where the rule was defined
+	Source string
+}
+
+// ParseIgnoreRule parses a rule of the form "pattern" or "pattern=category[,category...]"
+func ParseIgnoreRule(spec, source string) (IgnoreRule, error) {
+	pattern, categories, _ := strings.Cut(spec, "=")
+	rule := IgnoreRule{Pattern: strings.TrimSpace(pattern), Source: source}
+	if rule.Pattern == "" {
+		return IgnoreRule{}, fmt.Errorf("%w %q: no path pattern", ErrInvalidIgnoreRule, spec)
+	}
+
+	for _, segment := range strings.Split(rule.Pattern, ".") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return IgnoreRule{}, fmt.Errorf("%w %q: %v", ErrInvalidIgnoreRule, spec, err)
+		}
+	}
+
+	parsed, err := parseCategories(strings.FieldsFunc(categories, isListSeparator))
+	if err != nil {
+		return IgnoreRule{}, fmt.Errorf("%w %q: %v", ErrInvalidIgnoreRule, spec, err)
+	}
+	rule.Categories = parsed
+
+	return rule, nil
+}
+
+// Matches reports whether the rule suppresses a finding of the category at the path
+func (r IgnoreRule) Matches(findingPath string, category Category) bool {
+	if len(r.Categories) > 0 {
+		found := false
+		for _, c := range r.Categories {
+			if c == category {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return matchPathPrefix(strings.Split(r.Pattern, "."), strings.Split(findingPath, "."))
+}
+
+// String describes the rule for reports
+func (r IgnoreRule) String() string {
+	rule := r.Pattern
+	if len(r.Categories) > 0 {
+		names := make([]string, len(r.Categories))
+		for i, category := range r.Categories {
+			names[i] = string(category)
+		}
+		rule += "=" + strings.Join(names, ",")
+	}
+	if r.Source != "" {
+		rule += " (" + r.Source + ")"
+	}
+	return rule
+}
+
+// ParseAnnotations collects the hvm:keep and hvm:ignore comments of a values file as ignore rules.
+// An annotation applies to the key it is written on, or to the key that follows it.
+func ParseAnnotations(content []byte, file string) ([]IgnoreRule, error) {
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &document); err != nil {
+		return nil, &ValuesError{Err: err}
+	}
+
+	var rules []IgnoreRule
+	if len(document.Content) == 0 {
+		return rules, nil
+	}
+
+	err := collectAnnotations("", document.Content[0], file, &rules)
+	return rules, err
+}
+
+// collectAnnotations walks a mapping node and records the annotations of its keys
+func collectAnnotations(basePath string, node *yamlv3.Node, file string, rules *[]IgnoreRule) error {
+	if node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if keyNode.Tag == "!!merge" {
+			continue
+		}
+
+		currentPath := joinPath(basePath, keyNode.Value)
+		comments := []string{keyNode.HeadComment, keyNode.LineComment}
+		if valueNode.Kind == yamlv3.ScalarNode {
+			comments = append(comments, valueNode.LineComment)
+		}
+
+		for _, comment := range comments {
+			for _, line := range strings.Split(comment, "\n") {
+				categories, ok, err := parseAnnotation(line)
+				if err != nil {
+					return fmt.Errorf("%w at %s:%d: %v", ErrInvalidAnnotation, file, keyNode.Line, err)
+				}
+				if ok {
+					*rules = append(*rules, IgnoreRule{
+						Pattern:    currentPath,
+						Categories: categories,
+						Source:     fmt.Sprintf("%s:%d", file, keyNode.Line),
+					})
+				}
+			}
+		}
+
+		if err := collectAnnotations(currentPath, valueNode, file, rules); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseAnnotation extracts the suppressed categories of a comment line holding an annotation
+func parseAnnotation(line string) ([]Category, bool, error) {
+	fields := strings.Fields(strings.TrimLeft(strings.TrimSpace(line), "#"))
+	if len(fields) == 0 {
+		return nil, false, nil
+	}
+
+	switch fields[0] {
+	case AnnotationKeep:
+		return nil, true, nil
+	case AnnotationIgnore:
+		var names []string
+		for _, field := range fields[1:] {
+			names = append(names, strings.FieldsFunc(field, isListSeparator)...)
+		}
+		if len(names) == 0 {
+			return nil, false, fmt.Errorf("%s needs at least one category, use %s to suppress all of them", AnnotationIgnore, AnnotationKeep)
+		}
+		categories, err := parseCategories(names)
+		return categories, err == nil, err
+	default:
+		return nil, false, nil
+	}
+}
+
+// parseCategories validates finding category names
+func parseCategories(names []string) ([]Category, error) {
+	var categories []Category
+	for _, name := range names {
+		switch category := Category(name); category {
+		case CategoryRedundant, CategoryUnsupported, CategoryCommented, CategoryTypeMismatch, CategorySchemaViolation:
+			categories = append(categories, category)
+		default:
+			return nil, fmt.Errorf("unknown finding category %q", name)
+		}
+	}
+	return categories, nil
+}
+
+// matchPathPrefix reports whether the pattern segments match the path or one of its parents
+func matchPathPrefix(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchPathPrefix(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+		return false
+	}
+	return matchPathPrefix(pattern[1:], segments[1:])
+}
+
+// isListSeparator splits category lists on commas and spaces
+func isListSeparator(r rune) bool {
+	return r == ',' || r == ' '
+}
@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/xunholy/helm-values-manager/pkg/util"
+)
+
+// Policy defines the conditions under which an analysis fails a check
+type Policy struct {
+	FailOnUnsupported  bool
+	FailOnTypeMismatch bool
+	// FailOnSchemaViolation fails on values rejected by the schema given with WithSchema
+	FailOnSchemaViolation bool
+	// MaxRedundant is the number of redundant values tolerated, a negative value disables the limit
+	MaxRedundant int
+}
+
+// Violation describes a policy that the analysis results do not satisfy
+type Violation struct {
+	Category Category
+	Message  string
+}
+
+// Evaluate checks the analysis results against the policy and returns every violation
+func (p Policy) Evaluate(status ValueStatus) []Violation {
+	summary := status.Summarize()
+	violations := []Violation{}
+
+	if p.FailOnUnsupported && summary.Unsupported > 0 {
+		violations = append(violations, Violation{
+			Category: CategoryUnsupported,
+			Message:  fmt.Sprintf("found %s", util.Count(summary.Unsupported, "unsupported value")),
+		})
+	}
+
+	if p.FailOnTypeMismatch && summary.TypeMismatch > 0 {
+		violations = append(violations, Violation{
+			Category: CategoryTypeMismatch,
+			Message:  fmt.Sprintf("found %s with a type mismatch", util.Count(summary.TypeMismatch, "value")),
+		})
+	}
+
+	if p.FailOnSchemaViolation && summary.SchemaViolation > 0 {
+		violations = append(violations, Violation{
+			Category: CategorySchemaViolation,
+			Message:  fmt.Sprintf("found %s", util.Count(summary.SchemaViolation, "schema violation")),
+		})
+	}
+
+	if p.MaxRedundant >= 0 && summary.Redundant > p.MaxRedundant {
+		violations = append(violations, Violation{
+			Category: CategoryRedundant,
+			Message:  fmt.Sprintf("found %s, at most %d allowed", util.Count(summary.Redundant, "redundant value"), p.MaxRedundant),
+		})
+	}
+
+	return violations
+}
@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"errors"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Position is the location of a key in a values file
+type Position struct {
+	Line   int `yaml:"line" json:"line"`
+	Column int `yaml:"column" json:"column"`
+}
+
+// Positions maps dot-notation paths to the position of their key
+type Positions map[string]Position
+
+// ParseValues parses YAML values into a nested map and records the position of every key.
+// Duplicate keys are resolved like yaml.v2 does, the last occurrence wins.
+func ParseValues(content []byte) (map[string]interface{}, Positions, error) {
+	values := make(map[string]interface{})
+	positions := make(Positions)
+
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &document); err != nil {
+		return nil, nil, &ValuesError{Err: err}
+	}
+
+	// An empty document has no content
+	if len(document.Content) == 0 {
+		return values, positions, nil
+	}
+
+	root := resolveAlias(document.Content[0])
+	switch {
+	case root.Kind == yamlv3.MappingNode:
+		if err := decodeMapping("", root, values, positions); err != nil {
+			return nil, nil, err
+		}
+	case root.Kind == yamlv3.ScalarNode && root.Tag == "!!null":
+		// A document holding only comments or an explicit null has no values
+	default:
+		return nil, nil, &ValuesError{Line: root.Line, Err: errors.New("values must be a YAML mapping")}
+	}
+
+	return values, positions, nil
+}
+
+// decodeMapping decodes a mapping node into values, recording key positions under the given path
+func decodeMapping(path string, node *yamlv3.Node, values map[string]interface{}, positions Positions) error {
+	// Merge keys are applied first so that explicit keys of the mapping take precedence
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if keyNode.Tag != "!!merge" {
+			continue
+		}
+
+		if err := decodeMerge(path, valueNode, values, positions); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if keyNode.Tag == "!!merge" {
+			continue
+		}
+
+		key := keyNode.Value
+		currentPath := joinPath(path, key)
+		positions[currentPath] = Position{Line: keyNode.Line, Column: keyNode.Column}
+
+		value, err := decodeNode(currentPath, valueNode, positions)
+		if err != nil {
+			return err
+		}
+		values[key] = value
+	}
+
+	return nil
+}
+
+// decodeMerge applies the mappings referenced by a merge key
+func decodeMerge(path string, node *yamlv3.Node, values map[string]interface{}, positions Positions) error {
+	node = resolveAlias(node)
+
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		return decodeMapping(path, node, values, positions)
+	case yamlv3.SequenceNode:
+		// Earlier mappings in the sequence take precedence over later ones
+		for i := len(node.Content) - 1; i >= 0; i-- {
+			if err := decodeMerge(path, node.Content[i], values, positions); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return &ValuesError{Line: node.Line, Err: errors.New("merge key must reference a mapping")}
+	}
+}
+
+// decodeNode converts a YAML node into the equivalent Go value
+func decodeNode(path string, node *yamlv3.Node, positions Positions) (interface{}, error) {
+	node = resolveAlias(node)
+
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		nested := make(map[string]interface{})
+		if err := decodeMapping(path, node, nested, positions); err != nil {
+			return nil, err
+		}
+		return nested, nil
+	case yamlv3.SequenceNode:
+		items := make([]interface{}, 0, len(node.Content))
+		for _, itemNode := range node.Content {
+			// Positions are only tracked for mapping keys, list items share the path of the list
+			item, err := decodeNode(path, itemNode, Positions{})
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		// Helm reads values as YAML 1.1, where yes, no, on and off are booleans
+		if node.Style == 0 && node.Tag == "!!str" {
+			if value, ok := yaml11Bools[node.Value]; ok {
+				return value, nil
+			}
+		}
+
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return nil, &ValuesError{Line: node.Line, Err: err}
+		}
+		return value, nil
+	}
+}
+
+// yaml11Bools are the plain scalars YAML 1.1 reads as booleans and YAML 1.2 as strings
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true, "on": true, "On": true, "ON": true,
+	"n": false, "N": false, "no": false, "No": false, "NO": false, "off": false, "Off": false, "OFF": false,
+}
+
+// resolveAlias follows aliases to the node they reference
+func resolveAlias(node *yamlv3.Node) *yamlv3.Node {
+	for node.Kind == yamlv3.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
@@ -0,0 +1,164 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// SchemaDraft is the JSON Schema dialect of generated schemas, the one Helm validates values.schema.json against
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON Schema describing a values file
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Default     interface{}        `json:"default,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+}
+
+// GenerateSchema infers a JSON Schema from the defaults of a values file. Types come from the default values and
+// descriptions from the comments above each key. Null and empty string defaults accept any type, and objects accept keys the
+// defaults do not list, so that the schema only rejects values of the wrong type.
+func GenerateSchema(content []byte, title string) (*Schema, error) {
+	values, _, err := ParseValues(content)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := ParseKeyDocs(content)
+	if err != nil {
+		return nil, err
+	}
+	descriptions := make(map[string]string, len(docs))
+	for _, doc := range docs {
+		if doc.Description != "" && !doc.Commented {
+			descriptions[doc.Path] = doc.Description
+		}
+	}
+
+	schema := valueSchema("", values, descriptions)
+	schema.Schema = SchemaDraft
+	schema.Title = title
+	return schema, nil
+}
+
+// valueSchema describes a value and, for maps, each of its keys
+func valueSchema(path string, value interface{}, descriptions map[string]string) *Schema {
+	schema := &Schema{Description: descriptions[path], Type: schemaType(value)}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if len(typed) > 0 {
+			schema.Properties = make(map[string]*Schema, len(typed))
+		}
+		for key, child := range typed {
+			schema.Properties[key] = valueSchema(joinPath(path, key), child, descriptions)
+		}
+	case []interface{}:
+		schema.Items = itemsSchema(typed)
+		schema.Default = typed
+	case nil:
+	default:
+		schema.Default = typed
+	}
+
+	return schema
+}
+
+// itemsSchema describes the items of a list when they all have the same type
+func itemsSchema(items []interface{}) *Schema {
+	if len(items) == 0 {
+		return nil
+	}
+
+	itemType := schemaType(items[0])
+	for _, item := range items[1:] {
+		if kind := schemaType(item); kind != itemType {
+			if isNumberType(kind) && isNumberType(itemType) {
+				itemType = "number"
+				continue
+			}
+			return nil
+		}
+	}
+	if itemType == "" {
+		return nil
+	}
+	return &Schema{Type: itemType}
+}
+
+// schemaType returns the JSON Schema type of a value, or an empty string for the placeholder defaults which accept
+// any type. Whole numbers are integers, numbers written with a fraction or an exponent accept any number.
+func schemaType(value interface{}) string {
+	if untypedDefault(value) {
+		return ""
+	}
+	switch value.(type) {
+	case float32, float64:
+		return "number"
+	}
+
+	switch ValueKind(value) {
+	case KindMap:
+		return "object"
+	case KindList:
+		return "array"
+	case KindString:
+		return "string"
+	case KindBool:
+		return "boolean"
+	case KindNumber:
+		return "integer"
+	default:
+		return ""
+	}
+}
+
+// isNumberType reports whether a JSON Schema type is numeric
+func isNumberType(kind string) bool {
+	return kind == "integer" || kind == "number"
+}
+
+// SchemaViolation is a value rejected by the JSON Schema of a chart
+type SchemaViolation struct {
+	// Path is the key holding the rejected value, or the parent of a missing required key
+	Path    string
+	Message string
+}
+
+// ValidateValues validates downstream values against a JSON Schema such as the values.schema.json of a chart.
+// Like Helm, it validates the downstream values merged with the upstream defaults. Violations are sorted by path.
+func ValidateValues(schema []byte, upstream, downstream map[string]interface{}) ([]SchemaViolation, error) {
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	result, err := compiled.Validate(gojsonschema.NewGoLoader(coalesceValues(upstream, downstream)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate values against the schema: %w", err)
+	}
+
+	violations := make([]SchemaViolation, 0, len(result.Errors()))
+	for _, resultError := range result.Errors() {
+		path := resultError.Field()
+		if path == gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+			path = ""
+		}
+		// Missing required keys are reported on the key itself rather than on its parent
+		if property, ok := resultError.Details()["property"].(string); ok && resultError.Type() == "required" {
+			path = joinPath(path, property)
+		}
+		violations = append(violations, SchemaViolation{Path: path, Message: resultError.Description()})
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Path < violations[j].Path
+	})
+
+	return violations, nil
+}
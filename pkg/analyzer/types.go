@@ -10,6 +10,96 @@ type ValueStatus struct {
 	Unsupported map[string]interface{} `yaml:"unsupported,omitempty"`
 	Commented   map[string]interface{} `yaml:"commented,omitempty"`
 	Optimized   map[string]interface{} `yaml:"optimized,omitempty"`
+	Findings    []Finding              `yaml:"-"`
+	Source      string                 `yaml:"-"`
+	// Removed lists the downstream paths dropped from the optimized values, sorted by path
+	Removed []string `yaml:"-"`
+	// Suppressed lists the findings hidden by ignore rules, sorted by path
+	Suppressed []SuppressedFinding `yaml:"-"`
+	// Baselined lists the findings already known to the baseline, they are not reported
+	Baselined []Finding `yaml:"-"`
+	// OptimizedDocument is the optimized downstream file keeping its anchors and comments,
+	// unset when the analyzer was not given the downstream file
+	OptimizedDocument []byte `yaml:"-"`
+}
+
+// Category classifies a finding
+type Category string
+
+// Finding categories
+const (
+	CategoryRedundant    Category = "redundant"
+	CategoryUnsupported  Category = "unsupported"
+	CategoryCommented    Category = "commented"
+	CategoryTypeMismatch Category = "type-mismatch"
+	// CategorySchemaViolation marks a value rejected by the values.schema.json of the chart
+	CategorySchemaViolation Category = "schema-violation"
+)
+
+// Summary holds the number of findings per category
+type Summary struct {
+	Redundant       int `yaml:"redundant" json:"redundant"`
+	Unsupported     int `yaml:"unsupported" json:"unsupported"`
+	Commented       int `yaml:"commented" json:"commented"`
+	TypeMismatch    int `yaml:"typeMismatch" json:"typeMismatch"`
+	SchemaViolation int `yaml:"schemaViolation" json:"schemaViolation"`
+	Total           int `yaml:"total" json:"total"`
+	// Suppressed counts the findings hidden by ignore rules, they are not part of the total
+	Suppressed int `yaml:"suppressed" json:"suppressed"`
+	// Baselined counts the findings already known to the baseline, they are not part of the total
+	Baselined int `yaml:"baselined" json:"baselined"`
+}
+
+// Summarize counts the findings of the analysis per category
+func (v ValueStatus) Summarize() Summary {
+	summary := Summary{Total: len(v.Findings), Suppressed: len(v.Suppressed), Baselined: len(v.Baselined)}
+	for _, finding := range v.Findings {
+		switch finding.Category {
+		case CategoryRedundant:
+			summary.Redundant++
+		case CategoryUnsupported:
+			summary.Unsupported++
+		case CategoryCommented:
+			summary.Commented++
+		case CategoryTypeMismatch:
+			summary.TypeMismatch++
+		case CategorySchemaViolation:
+			summary.SchemaViolation++
+		}
+	}
+	return summary
+}
+
+// Finding is a single downstream value flagged by the analysis
+type Finding struct {
+	Path          string      `yaml:"path" json:"path"`
+	Category      Category    `yaml:"category" json:"category"`
+	Value         interface{} `yaml:"value" json:"value"`
+	UpstreamValue interface{} `yaml:"upstreamValue,omitempty" json:"upstreamValue,omitempty"`
+	File          string      `yaml:"file,omitempty" json:"file,omitempty"`
+	Line          int         `yaml:"line,omitempty" json:"line,omitempty"`
+	Column        int         `yaml:"column,omitempty" json:"column,omitempty"`
+	UpstreamLine  int         `yaml:"upstreamLine,omitempty" json:"upstreamLine,omitempty"`
+	Description   string      `yaml:"description,omitempty" json:"description,omitempty"`
+	// Message explains a schema violation
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+	// Anchor is the path where the value is written when the finding is on a copy made by an alias or merge key
+	Anchor string `yaml:"anchor,omitempty" json:"anchor,omitempty"`
+}
+
+// SuppressedFinding is a finding hidden by an ignore rule
+type SuppressedFinding struct {
+	Finding `yaml:",inline"`
+	Rule    string `yaml:"rule" json:"rule"`
+}
+
+// SuppressedByRule counts the suppressed findings per rule
+func (v ValueStatus) SuppressedByRule() map[string]int {
+	counts := make(map[string]int)
+	for _, suppressed := range v.Suppressed {
+		counts[suppressed.Rule]++
+	}
+	return counts
 }
 
 // ChangeRequest represents a value that needs to be modified
@@ -30,6 +120,14 @@ type PathOptions struct {
 	OptimizedValuesPath   string
 	UnsupportedValuesPath string
 	RedundantValuesPath   string
+	CommentedValuesPath   string
+	FindingsPath          string
+	MarkdownReportPath    string
+	HTMLReportPath        string
+	JUnitReportPath       string
+	JSONPatchPath         string
+	MergePatchPath        string
+	DiffPath              string
 }
 
 // NewPathOptions creates a new PathOptions with the given output directory
@@ -40,5 +138,13 @@ func NewPathOptions(outputDir string) PathOptions {
 		OptimizedValuesPath:   outputDir + "/optimized-values.yaml",
 		UnsupportedValuesPath: outputDir + "/unsupported-values.yaml",
 		RedundantValuesPath:   outputDir + "/redundant-values.yaml",
+		CommentedValuesPath:   outputDir + "/commented-values.yaml",
+		FindingsPath:          outputDir + "/findings.yaml",
+		MarkdownReportPath:    outputDir + "/report.md",
+		HTMLReportPath:        outputDir + "/report.html",
+		JUnitReportPath:       outputDir + "/junit.xml",
+		JSONPatchPath:         outputDir + "/optimized-values.patch.json",
+		MergePatchPath:        outputDir + "/optimized-values.merge-patch.json",
+		DiffPath:              outputDir + "/optimized-values.diff",
 	}
 }
@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"strings"
+)
+
+// Impacts of a chart upgrade reported by PlanUpgrade
+const (
+	// ImpactRemoved marks a downstream value whose key the target chart no longer defines
+	ImpactRemoved = "removed"
+	// ImpactTypeChanged marks a downstream value whose type no longer matches the target default
+	ImpactTypeChanged = "type-changed"
+	// ImpactPinned marks a downstream value equal to the current default, which keeps it after the upgrade
+	ImpactPinned = "pinned"
+	// ImpactRedundant marks a downstream value equal to the target default
+	ImpactRedundant = "redundant"
+	// ImpactDefaultChanged marks a default that changes for a key the downstream values do not set
+	ImpactDefaultChanged = "default-changed"
+)
+
+// UpgradeChange describes how a changed default of the chart affects the downstream values
+type UpgradeChange struct {
+	Path   string `yaml:"path" json:"path"`
+	Impact string `yaml:"impact" json:"impact"`
+	// Downstream is the downstream value of the key, unset for ImpactDefaultChanged
+	Downstream     interface{} `yaml:"downstream,omitempty" json:"downstream,omitempty"`
+	CurrentDefault interface{} `yaml:"currentDefault,omitempty" json:"currentDefault,omitempty"`
+	TargetDefault  interface{} `yaml:"targetDefault,omitempty" json:"targetDefault,omitempty"`
+}
+
+// PlanUpgrade compares the defaults of the current and target versions of a chart and reports, sorted by path,
+// how each changed default affects the downstream values. Keys the target chart adds are not reported, the
+// downstream values cannot set them yet.
+func PlanUpgrade(current, target, downstream map[string]interface{}) []UpgradeChange {
+	changes := []UpgradeChange{}
+
+	for _, change := range DiffValues(current, target) {
+		value, set := LookupValue(downstream, change.Path)
+		upgradeChange := UpgradeChange{
+			Path:           change.Path,
+			Downstream:     value,
+			CurrentDefault: change.OldValue,
+			TargetDefault:  change.NewValue,
+		}
+
+		switch {
+		case change.Type == ChangeAdded:
+			continue
+		case change.Type == ChangeRemoved:
+			if !set || freeFormInTarget(target, change.Path) {
+				continue
+			}
+			upgradeChange.Impact = ImpactRemoved
+		case !set:
+			upgradeChange.Impact = ImpactDefaultChanged
+		case equalValues(value, change.NewValue):
+			upgradeChange.Impact = ImpactRedundant
+		case equalValues(value, change.OldValue):
+			upgradeChange.Impact = ImpactPinned
+		case !untypedDefault(change.NewValue) && ValueKind(value) != ValueKind(change.NewValue) && !numericCompatible(value, change.NewValue):
+			upgradeChange.Impact = ImpactTypeChanged
+		default:
+			// The downstream value overrides both defaults, the upgrade does not change it
+			continue
+		}
+
+		changes = append(changes, upgradeChange)
+	}
+
+	return changes
+}
+
+// freeFormInTarget reports whether the closest parent of a path defined by the target values is an empty map,
+// which accepts any key
+func freeFormInTarget(target map[string]interface{}, path string) bool {
+	parts := strings.Split(path, ".")
+	for i := len(parts) - 1; i > 0; i-- {
+		value, ok := LookupValue(target, strings.Join(parts[:i], "."))
+		if !ok {
+			continue
+		}
+		parentMap, isMap := value.(map[string]interface{})
+		return isMap && len(parentMap) == 0
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LookupValue returns the value at a dot-notation path of nested values
+func LookupValue(values map[string]interface{}, path string) (interface{}, bool) {
+	parts := strings.Split(path, ".")
+	current := values
+
+	for i, part := range parts {
+		value, exists := current[part]
+		if !exists {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return value, true
+		}
+
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = nested
+	}
+
+	return nil, false
+}
+
+// SetValue sets the value at a dot-notation path, creating the missing parent maps.
+// It fails when a parent already holds a value that is not a map.
+func SetValue(values map[string]interface{}, path string, value interface{}) error {
+	parts := strings.Split(path, ".")
+	current := values
+
+	for i, part := range parts[:len(parts)-1] {
+		next, exists := current[part]
+		if !exists {
+			next = make(map[string]interface{})
+			current[part] = next
+		}
+
+		nested, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cannot set %s: %s is not a map", path, strings.Join(parts[:i+1], "."))
+		}
+		current = nested
+	}
+
+	current[parts[len(parts)-1]] = value
+	return nil
+}
+
+// DeleteValue removes the value at a dot-notation path, along with its parent when it becomes empty
+func DeleteValue(values map[string]interface{}, path string) {
+	removeNestedValue(values, path)
+}
+
+// DeepCopy returns a copy of nested values that shares no maps or lists with the original
+func DeepCopy(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	return deepCopy(values).(map[string]interface{})
+}
+
+// coalesceValues merges overrides into a copy of the defaults the way Helm does: maps are merged key by key,
+// other values replace the default and null removes it
+func coalesceValues(defaults, overrides map[string]interface{}) map[string]interface{} {
+	merged := DeepCopy(defaults)
+	if merged == nil {
+		merged = make(map[string]interface{})
+	}
+
+	for key, value := range overrides {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		overrideMap, overrideIsMap := value.(map[string]interface{})
+		defaultMap, defaultIsMap := merged[key].(map[string]interface{})
+		if overrideIsMap && defaultIsMap {
+			merged[key] = coalesceValues(defaultMap, overrideMap)
+			continue
+		}
+		merged[key] = deepCopy(value)
+	}
+
+	return merged
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the project configuration file
+const FileName = ".helm-values-manager.yaml"
+
+// EnvPrefix prefixes the environment variables overriding flags, e.g. HVM_MAX_REDUNDANT for -max-redundant
+const EnvPrefix = "HVM_"
+
+// ErrInvalidConfig is returned for configuration files and environment variables that cannot be applied
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// Settings holds flag values by flag name, repeatable flags hold one entry per occurrence
+type Settings map[string][]string
+
+// Find looks for the configuration file in dir and its parents and returns its path,
+// or an empty string when there is none
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory %s: %w", dir, err)
+	}
+
+	for {
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Config holds the settings of a configuration file
+type Config struct {
+	// Settings are the top-level keys, they set the flags of every command accepting them
+	Settings Settings
+	// Commands holds the sections named after a command, they set the flags of that command only
+	Commands map[string]Settings
+}
+
+// Load reads a configuration file. Keys are flag names, values are scalars or lists of scalars
+// for repeatable flags. A map under the name of a command is a section setting the flags of that command only.
+// known lists the flags of each command: top-level keys no command defines and section keys the command
+// does not define are rejected, as is the config key which cannot point to another file.
+// Relative paths of the given path flags are resolved against the file's directory.
+func Load(path string, known map[string]map[string]bool, pathFlags ...string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := yamlv3.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+
+	anyCommand := make(map[string]bool)
+	for _, flags := range known {
+		for name := range flags {
+			anyCommand[name] = true
+		}
+	}
+
+	cfg := &Config{Settings: Settings{}, Commands: map[string]Settings{}}
+	topLevel := make(map[string]interface{}, len(raw))
+	for name, value := range raw {
+		section, isMap := value.(map[string]interface{})
+		if !isMap {
+			topLevel[name] = value
+			continue
+		}
+		if _, isCommand := known[name]; !isCommand {
+			return nil, fmt.Errorf("%w: %s: %s is not a command, only commands have sections", ErrInvalidConfig, path, name)
+		}
+		settings, err := parseSettings(path, name+".", section, known[name], pathFlags)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Commands[name] = settings
+	}
+
+	if cfg.Settings, err = parseSettings(path, "", topLevel, anyCommand, pathFlags); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// For returns the settings of a command: the keys of its section over the top-level keys,
+// without the top-level keys skip reports
+func (c *Config) For(command string, skip func(name string) bool) Settings {
+	settings := make(Settings)
+	if c == nil {
+		return settings
+	}
+	for name, values := range c.Settings {
+		if !skip(name) {
+			settings[name] = values
+		}
+	}
+	for name, values := range c.Commands[command] {
+		settings[name] = values
+	}
+	return settings
+}
+
+// parseSettings converts the keys of the file or of one of its sections, prefixed in errors, to settings
+func parseSettings(path, prefix string, raw map[string]interface{}, known map[string]bool, pathFlags []string) (Settings, error) {
+	settings := make(Settings, len(raw))
+	for name, value := range raw {
+		if name == "config" {
+			return nil, fmt.Errorf("%w: %s cannot set the config key", ErrInvalidConfig, path)
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("%w: %s: unknown key %q", ErrInvalidConfig, path, prefix+name)
+		}
+
+		switch value := value.(type) {
+		case nil:
+			// An empty key keeps the flag default
+		case []interface{}:
+			for _, item := range value {
+				if !isScalar(item) {
+					return nil, fmt.Errorf("%w: %s: %s must be a scalar or a list of scalars", ErrInvalidConfig, path, prefix+name)
+				}
+				settings[name] = append(settings[name], fmt.Sprint(item))
+			}
+		default:
+			if !isScalar(value) {
+				return nil, fmt.Errorf("%w: %s: %s must be a scalar or a list of scalars", ErrInvalidConfig, path, prefix+name)
+			}
+			settings[name] = []string{fmt.Sprint(value)}
+		}
+	}
+
+	baseDir := filepath.Dir(path)
+	for _, name := range pathFlags {
+		for i, value := range settings[name] {
+			if value != "" && value != "-" && !filepath.IsAbs(value) {
+				settings[name][i] = filepath.Join(baseDir, value)
+			}
+		}
+	}
+
+	return settings, nil
+}
+
+// EnvName returns the environment variable overriding a flag
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// Apply sets the flags that were not given on the command line, from the environment first
+// and from the settings otherwise. Repeatable flags read a comma-separated list from the environment.
+// Settings of flags the flag set does not define are ignored, they belong to other commands.
+func Apply(fs *flag.FlagSet, settings Settings, lookupEnv func(string) (string, bool)) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || explicit[f.Name] {
+			return
+		}
+
+		values := settings[f.Name]
+		if env, ok := lookupEnv(EnvName(f.Name)); ok {
+			values = []string{env}
+			if _, repeatable := f.Value.(interface{ IsRepeatable() bool }); repeatable {
+				values = strings.Split(env, ",")
+			}
+		}
+
+		for _, value := range values {
+			if setErr := fs.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("%w: invalid value %q for %s: %v", ErrInvalidConfig, value, f.Name, setErr)
+				return
+			}
+		}
+	})
+
+	return err
+}
+
+// isScalar reports whether a decoded YAML value can be used as a flag value
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, bool, int, int64, uint64, float64:
+		return true
+	default:
+		return false
+	}
+}
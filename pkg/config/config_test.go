@@ -0,0 +1,238 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// listFlag is a repeatable flag collecting every occurrence
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func (l *listFlag) IsRepeatable() bool { return true }
+
+var testKnown = map[string]map[string]bool{
+	"analyze": {"output": true, "max-redundant": true, "ignore": true, "upstream": true},
+	"explain": {"max-redundant": true, "upstream": true},
+}
+
+// writeConfig writes a configuration file to a temporary directory and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApplyPrecedence(t *testing.T) {
+	const file = `max-redundant: 5
+ignore: [image.*, extra]
+analyze:
+  output: json
+`
+
+	tests := []struct {
+		name         string
+		args         []string
+		env          map[string]string
+		wantOutput   string
+		wantMax      int
+		wantIgnore   []string
+		wantExplicit bool
+	}{
+		{
+			name:       "file",
+			wantOutput: "json",
+			wantMax:    5,
+			wantIgnore: []string{"image.*", "extra"},
+		},
+		{
+			name:       "env over file",
+			env:        map[string]string{"HVM_MAX_REDUNDANT": "3", "HVM_IGNORE": "a,b", "HVM_OUTPUT": "sarif"},
+			wantOutput: "sarif",
+			wantMax:    3,
+			wantIgnore: []string{"a", "b"},
+		},
+		{
+			name:       "flag over env and file",
+			args:       []string{"-max-redundant", "1", "-ignore", "c", "-output", "html"},
+			env:        map[string]string{"HVM_MAX_REDUNDANT": "3", "HVM_IGNORE": "a,b", "HVM_OUTPUT": "sarif"},
+			wantOutput: "html",
+			wantMax:    1,
+			wantIgnore: []string{"c"},
+		},
+		{
+			name:       "flag set to its default",
+			args:       []string{"-max-redundant", "-1"},
+			wantOutput: "json",
+			wantMax:    -1,
+			wantIgnore: []string{"image.*", "extra"},
+		},
+	}
+
+	cfg, err := Load(writeConfig(t, file), testKnown)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+			output := fs.String("output", "yaml-files", "")
+			maxRedundant := fs.Int("max-redundant", -1, "")
+			var ignore listFlag
+			fs.Var(&ignore, "ignore", "")
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			lookupEnv := func(name string) (string, bool) {
+				value, ok := tt.env[name]
+				return value, ok
+			}
+			if err := Apply(fs, cfg.For("analyze", func(string) bool { return false }), lookupEnv); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+
+			if *output != tt.wantOutput || *maxRedundant != tt.wantMax {
+				t.Errorf("output/max-redundant = %s/%d, want %s/%d", *output, *maxRedundant, tt.wantOutput, tt.wantMax)
+			}
+			if !reflect.DeepEqual([]string(ignore), tt.wantIgnore) {
+				t.Errorf("ignore = %v, want %v", ignore, tt.wantIgnore)
+			}
+		})
+	}
+}
+
+func TestApplyInvalidValue(t *testing.T) {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	fs.Int("max-redundant", -1, "")
+
+	err := Apply(fs, Settings{"max-redundant": {"many"}}, func(string) (string, bool) { return "", false })
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Apply() error = %v, want %v", err, ErrInvalidConfig)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		skip    func(name string) bool
+		want    map[string]Settings
+		wantErr string
+	}{
+		{
+			name:    "top-level keys apply to every command",
+			content: "max-redundant: 2\n",
+			want: map[string]Settings{
+				"analyze": {"max-redundant": {"2"}},
+				"explain": {"max-redundant": {"2"}},
+			},
+		},
+		{
+			name:    "sections override top-level keys",
+			content: "max-redundant: 2\nexplain:\n  max-redundant: 0\n",
+			want: map[string]Settings{
+				"analyze": {"max-redundant": {"2"}},
+				"explain": {"max-redundant": {"0"}},
+			},
+		},
+		{
+			name:    "skipped top-level keys",
+			content: "output: json\nanalyze:\n  max-redundant: 1\n",
+			skip:    func(name string) bool { return name == "output" },
+			want: map[string]Settings{
+				"analyze": {"max-redundant": {"1"}},
+				"explain": {},
+			},
+		},
+		{
+			name:    "relative paths",
+			content: "upstream: chart/values.yaml\nexplain:\n  upstream: /abs/values.yaml\n",
+			want: map[string]Settings{
+				"analyze": {"upstream": {"<dir>/chart/values.yaml"}},
+				"explain": {"upstream": {"/abs/values.yaml"}},
+			},
+		},
+		{
+			name:    "empty key",
+			content: "max-redundant:\n",
+			want:    map[string]Settings{"analyze": {}, "explain": {}},
+		},
+		{
+			name:    "unknown key",
+			content: "colour: true\n",
+			wantErr: `unknown key "colour"`,
+		},
+		{
+			name:    "unknown section key",
+			content: "explain:\n  output: json\n",
+			wantErr: `unknown key "explain.output"`,
+		},
+		{
+			name:    "section of an unknown command",
+			content: "scan:\n  output: json\n",
+			wantErr: "scan is not a command",
+		},
+		{
+			name:    "config key",
+			content: "config: other.yaml\n",
+			wantErr: "cannot set the config key",
+		},
+		{
+			name:    "nested list",
+			content: "ignore: [[a]]\n",
+			wantErr: "ignore must be a scalar or a list of scalars",
+		},
+		{
+			name:    "invalid yaml",
+			content: "ignore: [\n",
+			wantErr: "invalid configuration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, tt.content)
+			cfg, err := Load(path, testKnown, "upstream")
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			skip := tt.skip
+			if skip == nil {
+				skip = func(string) bool { return false }
+			}
+			for command, want := range tt.want {
+				for _, values := range want {
+					for i, value := range values {
+						values[i] = strings.Replace(value, "<dir>", filepath.Dir(path), 1)
+					}
+				}
+				if got := cfg.For(command, skip); !reflect.DeepEqual(got, want) {
+					t.Errorf("For(%s) = %v, want %v", command, got, want)
+				}
+			}
+		})
+	}
+}
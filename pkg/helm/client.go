@@ -10,8 +10,10 @@ import (
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v2"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/release"
 )
 
 // Client represents a Helm client with configuration
@@ -49,7 +51,7 @@ func NewClient(context, namespace, kubeConfig string) (*Client, error) {
 		log.Info().Msgf(format, v)
 	})
 	if err != nil {
-		return nil, err
+		return nil, &Error{Op: "initialize client for context", Name: context, Kind: clusterErrorKind(err), Err: err}
 	}
 
 	client.Config = actionConfig
@@ -64,7 +66,7 @@ func (c *Client) FetchReleaseValues(releaseName string, revision int) (map[strin
 	// Fetch the latest release from the specified repository
 	rel, err := get.Run(releaseName)
 	if err != nil {
-		return nil, err
+		return nil, wrapReleaseError("get release", releaseName, err)
 	}
 
 	// Determine the release revision based on the specified revision
@@ -84,12 +86,97 @@ func (c *Client) FetchReleaseValues(releaseName string, revision int) (map[strin
 	// Fetch the values for the selected release
 	relVal, err := val.Run(rel.Name)
 	if err != nil {
-		return nil, err
+		return nil, wrapReleaseError("get values of release", rel.Name, err)
 	}
 
 	return relVal, nil
 }
 
+// FetchReleaseUserValues fetches only the user supplied values of a Helm release
+func (c *Client) FetchReleaseUserValues(releaseName string, revision int) (map[string]interface{}, error) {
+	// Create a new Helm GetValues action, pinned to the requested revision if one was given
+	val := action.NewGetValues(c.Config)
+	val.Version = revision
+
+	relVal, err := val.Run(releaseName)
+	if err != nil {
+		return nil, wrapReleaseError("get values of release", releaseName, err)
+	}
+
+	return relVal, nil
+}
+
+// ListReleases lists the deployed and failed releases in the client namespace, or in all namespaces,
+// optionally filtered by a label selector
+func (c *Client) ListReleases(allNamespaces bool, selector string) ([]*release.Release, error) {
+	actionConfig := c.Config
+
+	// Listing across namespaces requires a configuration that is not scoped to a namespace
+	if allNamespaces {
+		actionConfig = new(action.Configuration)
+		err := actionConfig.Init(c.Settings.RESTClientGetter(), "", os.Getenv("HELM_DRIVER"), func(format string, v ...interface{}) {
+			log.Info().Msgf(format, v...)
+		})
+		if err != nil {
+			return nil, &Error{Op: "initialize client for all namespaces", Kind: clusterErrorKind(err), Err: err}
+		}
+	}
+
+	// Create a new Helm List action with the specified configuration
+	list := action.NewList(actionConfig)
+	list.AllNamespaces = allNamespaces
+	list.Selector = selector
+	list.SetStateMask()
+
+	releases, err := list.Run()
+	if err != nil {
+		return nil, wrapReleaseError("list releases", "", err)
+	}
+	return releases, nil
+}
+
+// FetchReleaseChart fetches the chart embedded in a Helm release
+func (c *Client) FetchReleaseChart(releaseName string, revision int) (*chart.Chart, error) {
+	// Create a new Helm Get action, pinned to the requested revision if one was given
+	get := action.NewGet(c.Config)
+	get.Version = revision
+
+	rel, err := get.Run(releaseName)
+	if err != nil {
+		return nil, wrapReleaseError("get release", releaseName, err)
+	}
+
+	if rel.Chart == nil {
+		return nil, fmt.Errorf("release %s (revision %d) has no embedded chart", rel.Name, rel.Version)
+	}
+
+	if rel.Chart.Metadata != nil {
+		log.Info().Msgf("Using chart %s (version: %s) from release %s revision %d",
+			rel.Chart.Metadata.Name, rel.Chart.Metadata.Version, rel.Name, rel.Version)
+	}
+
+	return rel.Chart, nil
+}
+
+// ChartVersion returns the version of a chart, or an empty string when its metadata is missing
+func ChartVersion(ch *chart.Chart) string {
+	if ch.Metadata == nil {
+		return ""
+	}
+	return ch.Metadata.Version
+}
+
+// RawChartValues returns the raw values.yaml content of a chart, or nil if it is not available.
+// Releases read back from the cluster storage usually do not carry the raw chart files.
+func RawChartValues(ch *chart.Chart) []byte {
+	for _, f := range ch.Raw {
+		if f.Name == "values.yaml" {
+			return f.Data
+		}
+	}
+	return nil
+}
+
 // FetchChartValues gets values from a Helm chart repository or local file
 func FetchChartValues(chartName, version string) (map[string]interface{}, error) {
 	log.Info().Msgf("Fetching values from Helm chart: %s (version: %s)", chartName, version)
@@ -143,13 +230,15 @@ func FetchChartValues(chartName, version string) (map[string]interface{}, error)
 	cmdOutput, err := cmdExec.CombinedOutput()
 	if err != nil {
 		log.Error().Err(err).Str("output", string(cmdOutput)).Msg("Failed to run helm show values command")
-		return nil, fmt.Errorf("failed to fetch chart values: %s (output: %s)", err, string(cmdOutput))
+		return nil, wrapChartError("show values of chart", chartName, string(cmdOutput),
+			fmt.Errorf("failed to fetch chart values: %s (output: %s)", err, string(cmdOutput)))
 	}
 
 	// Check if the file exists and has content
 	if _, err := os.Stat(tempFile); err != nil || isFileEmpty(tempFile) {
 		log.Error().Msg("No values retrieved from helm command or chart not found")
-		return nil, fmt.Errorf("chart values not found or empty for: %s version: %s", chartName, version)
+		return nil, &Error{Op: "show values of chart", Name: chartName, Kind: ErrChartNotFound,
+			Err: fmt.Errorf("chart values not found or empty for version: %s", version)}
 	}
 
 	// Load the values from the temporary file
@@ -222,7 +311,8 @@ func FetchChartValuesRaw(chartName, version string) ([]byte, error) {
 	if err != nil {
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
-			return nil, fmt.Errorf("helm command failed: %s", string(exitErr.Stderr))
+			return nil, wrapChartError("show values of chart", chartName, string(exitErr.Stderr),
+				fmt.Errorf("helm command failed: %s", string(exitErr.Stderr)))
 		}
 		return nil, fmt.Errorf("failed to execute helm command: %w", err)
 	}
@@ -239,3 +329,40 @@ func FetchChartValuesRaw(chartName, version string) ([]byte, error) {
 
 	return output, nil
 }
+
+// FetchChartVersion resolves the version of a chart, the latest one when no version is requested.
+// Plain values files have no version, an empty string is returned for them.
+func FetchChartVersion(chartName, version string) (string, error) {
+	if _, err := os.Stat(chartName); err == nil {
+		if strings.HasSuffix(chartName, ".yaml") || strings.HasSuffix(chartName, ".yml") {
+			return "", nil
+		}
+
+		ch, err := loader.Load(chartName)
+		if err != nil {
+			return "", fmt.Errorf("failed to load chart: %w", err)
+		}
+		return ChartVersion(ch), nil
+	}
+
+	args := []string{"show", "chart", chartName}
+	if version != "" {
+		args = append(args, "--version", version)
+	}
+
+	output, err := exec.Command("helm", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", wrapChartError("show chart", chartName, string(exitErr.Stderr),
+				fmt.Errorf("helm command failed: %s", string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("failed to execute helm command: %w", err)
+	}
+
+	var metadata chart.Metadata
+	if err := yaml.Unmarshal(output, &metadata); err != nil {
+		return "", fmt.Errorf("failed to parse chart metadata: %w", err)
+	}
+	return metadata.Version, nil
+}
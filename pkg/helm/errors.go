@@ -0,0 +1,84 @@
+package helm
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// Kinds of failures reported by the client, test for them with errors.Is
+var (
+	ErrReleaseNotFound    = errors.New("release not found")
+	ErrChartNotFound      = errors.New("chart not found")
+	ErrClusterUnreachable = errors.New("cluster unreachable")
+)
+
+// Error describes a failed Helm operation
+type Error struct {
+	// Op is the operation that failed, e.g. "get release"
+	Op string
+	// Name is the release or chart the operation was about
+	Name string
+	// Kind is one of the Err* kinds, or nil when the failure is not classified
+	Kind error
+	// Err is the underlying error
+	Err error
+}
+
+func (e *Error) Error() string {
+	message := e.Op
+	if e.Name != "" {
+		message += " " + e.Name
+	}
+	if e.Kind != nil {
+		message += ": " + e.Kind.Error()
+	}
+	return message + ": " + e.Err.Error()
+}
+
+// Unwrap returns both the kind and the underlying error, so errors.Is matches either
+func (e *Error) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// wrapReleaseError classifies an error returned by the Helm actions operating on a release
+func wrapReleaseError(op, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	kind := clusterErrorKind(err)
+	if kind == nil && (errors.Is(err, driver.ErrReleaseNotFound) || strings.Contains(err.Error(), "has no deployed releases")) {
+		kind = ErrReleaseNotFound
+	}
+
+	return &Error{Op: op, Name: name, Kind: kind, Err: err}
+}
+
+// clusterErrorKind returns ErrClusterUnreachable when an error comes from failing to reach the cluster
+func clusterErrorKind(err error) error {
+	var urlErr *url.Error
+	var netErr net.Error
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) || strings.Contains(err.Error(), "cluster unreachable") {
+		return ErrClusterUnreachable
+	}
+	return nil
+}
+
+// wrapChartError classifies an error of the helm command fetching chart values from its output
+func wrapChartError(op, name string, output string, err error) error {
+	var kind error
+	lowered := strings.ToLower(output)
+	if strings.Contains(lowered, "not found") || strings.Contains(lowered, "no such file") ||
+		strings.Contains(lowered, "failed to download") || strings.Contains(lowered, "404") {
+		kind = ErrChartNotFound
+	}
+
+	return &Error{Op: op, Name: name, Kind: kind, Err: err}
+}
@@ -0,0 +1,65 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// ReleaseRevision describes a single revision of a Helm release
+type ReleaseRevision struct {
+	Revision     int
+	Updated      string
+	Status       string
+	Chart        string
+	AppVersion   string
+	Description  string
+	UserSupplied map[string]interface{}
+}
+
+// FetchReleaseHistory fetches every stored revision of a Helm release, oldest first
+func (c *Client) FetchReleaseHistory(releaseName string) ([]ReleaseRevision, error) {
+	// Create a new Helm History action with the specified configuration
+	history := action.NewHistory(c.Config)
+
+	releases, err := history.Run(releaseName)
+	if err != nil {
+		return nil, wrapReleaseError("get history of release", releaseName, err)
+	}
+
+	if len(releases) == 0 {
+		return nil, &Error{Op: "get history of release", Name: releaseName, Kind: ErrReleaseNotFound, Err: fmt.Errorf("no revisions found")}
+	}
+
+	revisions := make([]ReleaseRevision, 0, len(releases))
+	for _, rel := range releases {
+		revision := ReleaseRevision{
+			Revision:     rel.Version,
+			UserSupplied: rel.Config,
+		}
+
+		if rel.Info != nil {
+			revision.Status = rel.Info.Status.String()
+			revision.Description = rel.Info.Description
+			if !rel.Info.LastDeployed.IsZero() {
+				revision.Updated = rel.Info.LastDeployed.Format(time.RFC3339)
+			}
+		}
+
+		if rel.Chart != nil && rel.Chart.Metadata != nil {
+			revision.Chart = fmt.Sprintf("%s-%s", rel.Chart.Metadata.Name, rel.Chart.Metadata.Version)
+			revision.AppVersion = rel.Chart.Metadata.AppVersion
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	// The storage backend does not guarantee any ordering
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+
+	return revisions, nil
+}
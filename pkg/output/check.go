@@ -0,0 +1,46 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"github.com/xunholy/helm-values-manager/pkg/util"
+)
+
+// WriteCheckSummary prints a concise summary of a check, listing the offending findings when it fails
+func WriteCheckSummary(w io.Writer, valueStatus analyzer.ValueStatus, violations []analyzer.Violation) {
+	summary := valueStatus.Summarize()
+	fmt.Fprintf(w, "%d unsupported, %d redundant, %d commented, %d type mismatch\n",
+		summary.Unsupported, summary.Redundant, summary.Commented, summary.TypeMismatch)
+	if summary.SchemaViolation > 0 {
+		fmt.Fprintln(w, util.Count(summary.SchemaViolation, "schema violation"))
+	}
+	if summary.Suppressed > 0 {
+		fmt.Fprintf(w, "%d suppressed by ignore rules\n", summary.Suppressed)
+	}
+	if summary.Baselined > 0 {
+		fmt.Fprintf(w, "%d already in the baseline\n", summary.Baselined)
+	}
+
+	if len(violations) == 0 {
+		fmt.Fprintln(w, "PASS")
+		return
+	}
+
+	for _, violation := range violations {
+		fmt.Fprintf(w, "FAIL: %s\n", violation.Message)
+
+		for _, finding := range valueStatus.Findings {
+			if finding.Category != violation.Category {
+				continue
+			}
+
+			if finding.Message != "" {
+				fmt.Fprintf(w, "  %s: %s: %s\n", findingLocation(finding), finding.Path, finding.Message)
+				continue
+			}
+			fmt.Fprintf(w, "  %s: %s\n", findingLocation(finding), finding.Path)
+		}
+	}
+}
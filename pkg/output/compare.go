@@ -0,0 +1,75 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"github.com/xunholy/helm-values-manager/pkg/util"
+)
+
+// WriteContextComparison writes the per-path differences of a release across kube contexts
+func WriteContextComparison(comparison analyzer.ContextComparison, format, outputDir string) error {
+	// Highlight keys that are only set in some of the clusters
+	for _, difference := range comparison.Differences {
+		if len(difference.MissingIn) > 0 {
+			log.Warn().Msgf("%s is not set in: %s", difference.Path, strings.Join(difference.MissingIn, ", "))
+		}
+	}
+
+	log.Info().Msgf("Found %d differing values across %d contexts", len(comparison.Differences), len(comparison.Contexts))
+
+	content, extension, err := marshalReport(comparison, format, func() string {
+		return renderComparisonMarkdown(comparison)
+	})
+	if err != nil {
+		return wrapError("context comparison", err)
+	}
+
+	comparisonFilePath := filepath.Join(outputDir, "context-comparison."+extension)
+	if err := util.CreateOutputFile(content, comparisonFilePath); err != nil {
+		return wrapError("context comparison", err)
+	}
+
+	log.Info().Msgf("Context comparison written to: %s", comparisonFilePath)
+	return nil
+}
+
+// renderComparisonMarkdown renders a context comparison as a Markdown table with one column per context
+func renderComparisonMarkdown(comparison analyzer.ContextComparison) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Values of release `%s` across contexts\n\n", comparison.Release)
+
+	if len(comparison.Differences) == 0 {
+		b.WriteString("All contexts have identical user supplied values.\n")
+		return b.String()
+	}
+
+	b.WriteString("| Path |")
+	for _, context := range comparison.Contexts {
+		fmt.Fprintf(&b, " %s |", context)
+	}
+	b.WriteString("\n|------|")
+	for range comparison.Contexts {
+		b.WriteString("------|")
+	}
+	b.WriteString("\n")
+
+	for _, difference := range comparison.Differences {
+		fmt.Fprintf(&b, "| `%s` |", difference.Path)
+		for _, context := range comparison.Contexts {
+			value, exists := difference.Values[context]
+			if !exists {
+				b.WriteString(" _not set_ |")
+				continue
+			}
+			fmt.Fprintf(&b, " %s |", markdownValue(value))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
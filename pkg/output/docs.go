@@ -0,0 +1,107 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"gopkg.in/yaml.v2"
+)
+
+// Markers delimiting the generated values documentation in a README
+const (
+	DocsStartMarker = "<!-- helm-values-manager:docs:start -->"
+	DocsEndMarker   = "<!-- helm-values-manager:docs:end -->"
+)
+
+// DocsData is the model values documentation templates are executed against
+type DocsData struct {
+	Metadata
+	// Keys lists the documented keys in the order of the values file: every key holding a value,
+	// the maps that have a description and the keys that are only commented out
+	Keys []analyzer.KeyDoc
+}
+
+// defaultDocsTemplate renders the documented keys as a Markdown table
+const defaultDocsTemplate = `| Key | Type | Default | Description |
+|-----|------|---------|-------------|
+{{- range .Keys }}
+| ` + "`{{ .Path }}`" + ` | {{ .Type }} | {{ markdownValue .Default }} | {{ markdownText .Description }}{{ if .Example }}{{ if .Description }}<br>{{ end }}Example: {{ markdownExample .Example }}{{ end }} |
+{{- end }}
+`
+
+// docsFuncs are the helper functions available to documentation templates, in addition to those of report templates
+var docsFuncs = template.FuncMap{
+	"markdownValue": markdownValue,
+	"markdownText": func(text string) string {
+		return strings.ReplaceAll(text, "|", "\\|")
+	},
+	"markdownExample": func(example string) string {
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(example), &value); err != nil || value == nil {
+			return "`" + strings.ReplaceAll(strings.Join(strings.Fields(example), " "), "|", "\\|") + "`"
+		}
+		return markdownValue(value)
+	},
+}
+
+// NewDocsData builds the documentation model from the keys of a values file
+func NewDocsData(docs []analyzer.KeyDoc, metadata Metadata) DocsData {
+	data := DocsData{Metadata: metadata, Keys: []analyzer.KeyDoc{}}
+	for _, doc := range docs {
+		// Maps holding keys are documented through their children unless they are described themselves
+		if doc.Type == analyzer.KindMap && analyzer.CountNestedKeys(toStringMap(doc.Default)) > 0 && doc.Description == "" {
+			continue
+		}
+		data.Keys = append(data.Keys, doc)
+	}
+	return data
+}
+
+// RenderDocs executes the documentation template, the Markdown table when templatePath is empty
+func RenderDocs(data DocsData, templatePath string) (string, error) {
+	name, content := "docs", defaultDocsTemplate
+	if templatePath != "" {
+		raw, err := os.ReadFile(templatePath)
+		if err != nil {
+			return "", fmt.Errorf("%w: failed to read: %v", ErrInvalidTemplate, err)
+		}
+		name, content = filepath.Base(templatePath), string(raw)
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Funcs(docsFuncs).Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("%w: failed to execute: %v", ErrInvalidTemplate, err)
+	}
+
+	return b.String(), nil
+}
+
+// UpdateReadme replaces the content between the documentation markers of a README
+func UpdateReadme(readme []byte, docs string) ([]byte, error) {
+	content := string(readme)
+	start := strings.Index(content, DocsStartMarker)
+	end := strings.Index(content, DocsEndMarker)
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("%w: expected %s followed by %s", ErrDocsMarkers, DocsStartMarker, DocsEndMarker)
+	}
+
+	start += len(DocsStartMarker)
+	docs = strings.TrimSuffix(docs, "\n")
+	return []byte(content[:start] + "\n" + docs + "\n" + content[end:]), nil
+}
+
+// toStringMap returns a map value, or nil for other values
+func toStringMap(value interface{}) map[string]interface{} {
+	m, _ := value.(map[string]interface{})
+	return m
+}
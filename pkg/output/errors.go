@@ -0,0 +1,49 @@
+package output
+
+import (
+	"errors"
+)
+
+// Kinds of output failures, test for them with errors.Is
+var (
+	ErrUnknownFormat   = errors.New("unknown output format")
+	ErrInvalidTemplate = errors.New("invalid report template")
+	ErrWriteFailed     = errors.New("failed to write output")
+	ErrDocsMarkers     = errors.New("missing documentation markers")
+)
+
+// Error describes a failure to produce an output
+type Error struct {
+	// Op is the output being produced, e.g. a format name
+	Op string
+	// Kind is one of the Err* kinds
+	Kind error
+	// Err is the underlying error
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Op + " output: " + e.Err.Error()
+}
+
+// Unwrap returns both the kind and the underlying error, so errors.Is matches either
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// wrapError classifies an output error, failures that are not a bad format, template or README are write failures
+func wrapError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	kind := ErrWriteFailed
+	for _, known := range []error{ErrUnknownFormat, ErrInvalidTemplate, ErrDocsMarkers} {
+		if errors.Is(err, known) {
+			kind = known
+			break
+		}
+	}
+
+	return &Error{Op: op, Kind: kind, Err: err}
+}
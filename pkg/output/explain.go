@@ -0,0 +1,126 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"gopkg.in/yaml.v2"
+)
+
+// WriteExplanation prints the explanation of a key as text, or encoded as json or yaml
+func WriteExplanation(w io.Writer, explanation analyzer.Explanation, upstreamName, format string) error {
+	switch format {
+	case "json":
+		content, err := json.MarshalIndent(jsonExplanation(explanation), "", "  ")
+		if err != nil {
+			return wrapError("explain", err)
+		}
+		_, err = fmt.Fprintln(w, string(content))
+		return wrapError("explain", err)
+	case "yaml":
+		content, err := yaml.Marshal(explanation)
+		if err != nil {
+			return wrapError("explain", err)
+		}
+		_, err = w.Write(content)
+		return wrapError("explain", err)
+	case "text", "":
+		_, err := io.WriteString(w, explanationText(explanation, upstreamName))
+		return wrapError("explain", err)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+// explanationText renders an explanation for a terminal
+func explanationText(explanation analyzer.Explanation, upstreamName string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Key:      %s\n", explanation.Path)
+	switch {
+	case explanation.Upstream:
+		fmt.Fprintf(&b, "Type:     %s\n", explanation.Type)
+		writeField(&b, "Default:  ", explanation.Default)
+	case explanation.Commented:
+		b.WriteString("Default:  none, the key is only a commented-out example\n")
+	default:
+		b.WriteString("Default:  none, the key is not defined by the chart\n")
+	}
+	if explanation.Line > 0 {
+		fmt.Fprintf(&b, "Source:   %s:%d\n", upstreamName, explanation.Line)
+	}
+
+	if explanation.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", explanation.Description)
+	}
+
+	if explanation.Example != "" {
+		b.WriteString("\nExample:\n")
+		for _, line := range strings.Split(explanation.Example, "\n") {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	if explanation.DownstreamFile == "" && !explanation.DownstreamSet && len(explanation.Findings) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("\n")
+	if explanation.DownstreamSet {
+		label := "Downstream"
+		if explanation.DownstreamFile != "" {
+			location := explanation.DownstreamFile
+			if explanation.DownstreamLine > 0 {
+				location = fmt.Sprintf("%s:%d", location, explanation.DownstreamLine)
+			}
+			label += " (" + location + ")"
+		}
+		writeField(&b, label+": ", explanation.Downstream)
+		if len(explanation.Findings) == 0 && explanation.Upstream {
+			b.WriteString("  overrides the default\n")
+		}
+	} else {
+		b.WriteString("Downstream: not set, the default applies\n")
+	}
+
+	for _, finding := range explanation.Findings {
+		message := sarifRules[finding.Category].Description
+		if finding.Path != explanation.Path {
+			message = fmt.Sprintf("%s (at %s)", message, finding.Path)
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", finding.Category, message)
+	}
+
+	return b.String()
+}
+
+// writeField writes a labelled value as YAML, values spanning several lines start below the label and are indented
+func writeField(b *strings.Builder, label string, value interface{}) {
+	text := fmt.Sprintf("%v", value)
+	if content, err := yaml.Marshal(value); err == nil {
+		text = strings.TrimRight(string(content), "\n")
+	}
+
+	kind := analyzer.ValueKind(value)
+	inline := text == "{}" || text == "[]" || (kind != analyzer.KindMap && kind != analyzer.KindList)
+	if inline && !strings.Contains(text, "\n") {
+		fmt.Fprintf(b, "%s%s\n", label, text)
+		return
+	}
+	fmt.Fprintf(b, "%s\n  %s\n", strings.TrimRight(label, " "), strings.ReplaceAll(text, "\n", "\n  "))
+}
+
+// jsonExplanation converts the values of an explanation for encoding/json
+func jsonExplanation(explanation analyzer.Explanation) analyzer.Explanation {
+	explanation.Default = jsonValue(explanation.Default)
+	explanation.Downstream = jsonValue(explanation.Downstream)
+	explanation.Findings = append([]analyzer.Finding(nil), explanation.Findings...)
+	for i := range explanation.Findings {
+		explanation.Findings[i].Value = jsonValue(explanation.Findings[i].Value)
+		explanation.Findings[i].UpstreamValue = jsonValue(explanation.Findings[i].UpstreamValue)
+	}
+	return explanation
+}
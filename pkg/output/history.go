@@ -0,0 +1,67 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"github.com/xunholy/helm-values-manager/pkg/util"
+)
+
+// WriteTimeline writes a release history timeline to the output directory in the given format
+func WriteTimeline(timeline analyzer.Timeline, format, outputDir string) error {
+	content, extension, err := marshalReport(timeline, format, func() string {
+		return renderTimelineMarkdown(timeline)
+	})
+	if err != nil {
+		return wrapError("release history", err)
+	}
+
+	historyFilePath := filepath.Join(outputDir, "release-history."+extension)
+	if err := util.CreateOutputFile(content, historyFilePath); err != nil {
+		return wrapError("release history", err)
+	}
+
+	log.Info().Msgf("Release history written to: %s", historyFilePath)
+	return nil
+}
+
+// renderTimelineMarkdown renders a release history timeline as a Markdown document
+func renderTimelineMarkdown(timeline analyzer.Timeline) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Values history for release `%s`\n", timeline.Release)
+
+	for _, revision := range timeline.Revisions {
+		fmt.Fprintf(&b, "\n## Revision %d\n\n", revision.Revision)
+
+		if revision.Updated != "" {
+			fmt.Fprintf(&b, "- **Updated:** %s\n", revision.Updated)
+		}
+		if revision.Status != "" {
+			fmt.Fprintf(&b, "- **Status:** %s\n", revision.Status)
+		}
+		if revision.Chart != "" {
+			fmt.Fprintf(&b, "- **Chart:** %s\n", revision.Chart)
+		}
+		if revision.Description != "" {
+			fmt.Fprintf(&b, "- **Description:** %s\n", revision.Description)
+		}
+
+		if len(revision.Changes) == 0 {
+			b.WriteString("\nNo user supplied values changed.\n")
+			continue
+		}
+
+		b.WriteString("\n| Change | Path | Old value | New value |\n")
+		b.WriteString("|--------|------|-----------|-----------|\n")
+		for _, change := range revision.Changes {
+			fmt.Fprintf(&b, "| %s | `%s` | %s | %s |\n",
+				change.Type, change.Path, markdownValue(change.OldValue), markdownValue(change.NewValue))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"gopkg.in/yaml.v2"
+)
+
+// htmlReportTemplate is a self-contained HTML page, all styles are inlined
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"yaml":        htmlValue,
+	"location":    findingLocation,
+	"description": findingDescription,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Helm values analysis</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #d0d7de; padding: 6px 12px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+pre { margin: 0; font-size: 0.9em; white-space: pre-wrap; }
+code { font-size: 0.9em; }
+summary { cursor: pointer; font-weight: bold; font-size: 1.1em; margin-top: 1em; }
+</style>
+</head>
+<body>
+<h1>Helm values analysis</h1>
+<table>
+<tr><th>Category</th><th>Count</th></tr>
+{{- range .}}
+<tr><td>{{.Title}}</td><td>{{len .Findings}}</td></tr>
+{{- end}}
+</table>
+{{- range .}}
+{{- if .Findings}}
+<details>
+<summary>{{.Title}} ({{len .Findings}})</summary>
+<table>
+<tr><th>Path</th><th>Location</th><th>Downstream</th><th>Upstream</th><th>Description</th></tr>
+{{- range .Findings}}
+<tr><td><code>{{.Path}}</code></td><td>{{location .}}</td><td><pre>{{yaml .Value}}</pre></td><td><pre>{{yaml .UpstreamValue}}</pre></td><td>{{description .}}</td></tr>
+{{- end}}
+</table>
+</details>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// RenderHTMLReport renders the analysis results as a self-contained HTML page
+func RenderHTMLReport(valueStatus analyzer.ValueStatus) (string, error) {
+	var b bytes.Buffer
+	if err := htmlReportTemplate.Execute(&b, reportSections(valueStatus)); err != nil {
+		return "", fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return b.String(), nil
+}
+
+// htmlValue renders a value as YAML for display in a preformatted block
+func htmlValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	encoded, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.TrimSuffix(string(encoded), "\n")
+}
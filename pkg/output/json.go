@@ -0,0 +1,54 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+)
+
+// Report is the machine-readable document describing a complete analysis
+type Report struct {
+	Summary  analyzer.Summary   `json:"summary"`
+	Findings []analyzer.Finding `json:"findings"`
+	// Suppressed lists the findings hidden by ignore rules
+	Suppressed []analyzer.SuppressedFinding `json:"suppressed,omitempty"`
+}
+
+// NewReport builds a report from the analysis results
+func NewReport(valueStatus analyzer.ValueStatus) Report {
+	report := Report{
+		Summary:  valueStatus.Summarize(),
+		Findings: make([]analyzer.Finding, 0, len(valueStatus.Findings)),
+	}
+
+	for _, finding := range valueStatus.Findings {
+		// Values decoded from YAML may contain maps that encoding/json cannot handle
+		finding.Value = jsonValue(finding.Value)
+		finding.UpstreamValue = jsonValue(finding.UpstreamValue)
+		report.Findings = append(report.Findings, finding)
+	}
+
+	for _, suppressed := range valueStatus.Suppressed {
+		suppressed.Value = jsonValue(suppressed.Value)
+		suppressed.UpstreamValue = jsonValue(suppressed.UpstreamValue)
+		report.Suppressed = append(report.Suppressed, suppressed)
+	}
+
+	return report
+}
+
+// WriteJSONReport writes the analysis results as a single JSON document
+func WriteJSONReport(w io.Writer, valueStatus analyzer.ValueStatus) error {
+	content, err := json.MarshalIndent(NewReport(valueStatus), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON report: %w", err)
+	}
+
+	if _, err := fmt.Fprintln(w, string(content)); err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitCategories are the finding categories reported as test cases, each backed by a policy
+var junitCategories = []analyzer.Category{
+	analyzer.CategoryUnsupported,
+	analyzer.CategoryTypeMismatch,
+	analyzer.CategorySchemaViolation,
+	analyzer.CategoryRedundant,
+}
+
+// WriteJUnitReport writes the analysis results as JUnit XML. The downstream file is a test suite and
+// every policy is a test case that fails with the offending paths when the policy is violated.
+func WriteJUnitReport(w io.Writer, valueStatus analyzer.ValueStatus, policy analyzer.Policy) error {
+	suiteName := valueStatus.Source
+	if suiteName == "" {
+		suiteName = "values"
+	}
+
+	violations := make(map[analyzer.Category]analyzer.Violation)
+	for _, violation := range policy.Evaluate(valueStatus) {
+		violations[violation.Category] = violation
+	}
+
+	suite := junitTestSuite{Name: suiteName}
+	for _, category := range junitCategories {
+		testCase := junitTestCase{Name: string(category), ClassName: suiteName}
+
+		if violation, violated := violations[category]; violated {
+			var offending []string
+			for _, finding := range valueStatus.Findings {
+				if finding.Category == category {
+					line := fmt.Sprintf("%s (%s)", finding.Path, findingLocation(finding))
+					if finding.Message != "" {
+						line += ": " + finding.Message
+					}
+					offending = append(offending, line)
+				}
+			}
+
+			testCase.Failure = &junitFailure{
+				Message: violation.Message,
+				Type:    string(category),
+				Text:    strings.Join(offending, "\n"),
+			}
+			suite.Failures++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+	}
+
+	content, err := xml.MarshalIndent(junitTestSuites{
+		Name:     toolName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(w, "%s%s\n", xml.Header, content); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package output
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+)
+
+func TestWriteJUnitReport(t *testing.T) {
+	status := analyzer.ValueStatus{
+		Source: "values.yaml",
+		Findings: []analyzer.Finding{
+			{Path: "extra", Category: analyzer.CategoryUnsupported, File: "values.yaml", Line: 4},
+			{Path: "image.tag", Category: analyzer.CategoryRedundant, File: "values.yaml", Line: 2},
+			{Path: "replicas", Category: analyzer.CategoryTypeMismatch, File: "values.yaml", Line: 3},
+			{Path: "service.type", Category: analyzer.CategorySchemaViolation, File: "values.yaml", Line: 6,
+				Message: `service.type must be one of the following: "ClusterIP"`},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		policy analyzer.Policy
+		// failures maps the failing test cases to a line their failure must contain
+		failures map[string]string
+	}{
+		{
+			name:   "no policy",
+			policy: analyzer.Policy{MaxRedundant: -1},
+		},
+		{
+			name:     "unsupported",
+			policy:   analyzer.Policy{FailOnUnsupported: true, MaxRedundant: -1},
+			failures: map[string]string{"unsupported": "extra (values.yaml:4)"},
+		},
+		{
+			name:     "type mismatch",
+			policy:   analyzer.Policy{FailOnTypeMismatch: true, MaxRedundant: -1},
+			failures: map[string]string{"type-mismatch": "replicas (values.yaml:3)"},
+		},
+		{
+			name:   "schema violation",
+			policy: analyzer.Policy{FailOnSchemaViolation: true, MaxRedundant: -1},
+			failures: map[string]string{
+				"schema-violation": `service.type (values.yaml:6): service.type must be one of the following: "ClusterIP"`,
+			},
+		},
+		{
+			name:     "redundant",
+			policy:   analyzer.Policy{MaxRedundant: 0},
+			failures: map[string]string{"redundant": "image.tag (values.yaml:2)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := WriteJUnitReport(&b, status, tt.policy); err != nil {
+				t.Fatalf("WriteJUnitReport() error = %v", err)
+			}
+
+			var report junitTestSuites
+			if err := xml.Unmarshal([]byte(b.String()), &report); err != nil {
+				t.Fatalf("invalid JUnit XML: %v", err)
+			}
+			if len(report.Suites) != 1 || report.Suites[0].Name != "values.yaml" {
+				t.Fatalf("suites = %+v, want one suite for values.yaml", report.Suites)
+			}
+
+			suite := report.Suites[0]
+			if suite.Tests != len(junitCategories) || suite.Failures != len(tt.failures) || report.Failures != len(tt.failures) {
+				t.Errorf("tests/failures = %d/%d, want %d/%d", suite.Tests, suite.Failures, len(junitCategories), len(tt.failures))
+			}
+
+			violations := make(map[analyzer.Category]string)
+			for _, violation := range tt.policy.Evaluate(status) {
+				violations[violation.Category] = violation.Message
+			}
+			for _, testCase := range suite.TestCases {
+				want, failing := tt.failures[testCase.Name]
+				if !failing {
+					if testCase.Failure != nil {
+						t.Errorf("%s fails with %q, want it to pass", testCase.Name, testCase.Failure.Message)
+					}
+					continue
+				}
+				if testCase.Failure == nil {
+					t.Errorf("%s passes, want it to fail", testCase.Name)
+					continue
+				}
+				if testCase.Failure.Message != violations[analyzer.Category(testCase.Name)] {
+					t.Errorf("%s message = %q, want the violation message", testCase.Name, testCase.Failure.Message)
+				}
+				if !strings.Contains(testCase.Failure.Text, want) {
+					t.Errorf("%s failure = %q, want it to list %q", testCase.Name, testCase.Failure.Text, want)
+				}
+			}
+		})
+	}
+}
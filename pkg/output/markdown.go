@@ -0,0 +1,105 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+)
+
+// reportSection groups the findings of a single category for human-readable reports
+type reportSection struct {
+	Title    string
+	Category analyzer.Category
+	Findings []analyzer.Finding
+}
+
+// reportSections groups the findings by category, in the order they are presented in reports
+func reportSections(valueStatus analyzer.ValueStatus) []reportSection {
+	sections := []reportSection{
+		{Title: "Unsupported values", Category: analyzer.CategoryUnsupported},
+		{Title: "Type mismatches", Category: analyzer.CategoryTypeMismatch},
+		{Title: "Schema violations", Category: analyzer.CategorySchemaViolation},
+		{Title: "Redundant values", Category: analyzer.CategoryRedundant},
+		{Title: "Commented values", Category: analyzer.CategoryCommented},
+	}
+
+	for i := range sections {
+		for _, finding := range valueStatus.Findings {
+			if finding.Category == sections[i].Category {
+				sections[i].Findings = append(sections[i].Findings, finding)
+			}
+		}
+	}
+
+	return sections
+}
+
+// findingLocation formats the source location of a finding as file:line, naming the anchor definition
+// of values copied by an alias or merge key
+func findingLocation(finding analyzer.Finding) string {
+	location := finding.File
+	if finding.Line > 0 {
+		location = fmt.Sprintf("%s:%d", finding.File, finding.Line)
+	}
+	if finding.Anchor != "" {
+		location += fmt.Sprintf(" (via %s)", finding.Anchor)
+	}
+	return location
+}
+
+// findingDescription describes a finding for reports, schema violations by the rules they break
+func findingDescription(finding analyzer.Finding) string {
+	if finding.Message != "" {
+		return finding.Message
+	}
+	return finding.Description
+}
+
+// RenderMarkdownReport renders the analysis results as a Markdown document suitable for PR comments
+func RenderMarkdownReport(valueStatus analyzer.ValueStatus) string {
+	var b strings.Builder
+	sections := reportSections(valueStatus)
+
+	b.WriteString("# Helm values analysis\n\n")
+	b.WriteString("| Category | Count |\n")
+	b.WriteString("|----------|-------|\n")
+	for _, section := range sections {
+		fmt.Fprintf(&b, "| %s | %d |\n", section.Title, len(section.Findings))
+	}
+
+	for _, section := range sections {
+		if len(section.Findings) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n<details>\n<summary><b>%s (%d)</b></summary>\n\n", section.Title, len(section.Findings))
+		b.WriteString("| Path | Location | Downstream | Upstream | Description |\n")
+		b.WriteString("|------|----------|------------|----------|-------------|\n")
+		for _, finding := range section.Findings {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n",
+				finding.Path,
+				findingLocation(finding),
+				markdownValue(finding.Value),
+				markdownValue(finding.UpstreamValue),
+				strings.ReplaceAll(findingDescription(finding), "|", "\\|"))
+		}
+		b.WriteString("\n</details>\n")
+	}
+
+	if len(valueStatus.Suppressed) > 0 {
+		fmt.Fprintf(&b, "\n<details>\n<summary><b>Suppressed findings (%d)</b></summary>\n\n", len(valueStatus.Suppressed))
+		b.WriteString("| Path | Category | Location | Rule |\n")
+		b.WriteString("|------|----------|----------|------|\n")
+		for _, suppressed := range valueStatus.Suppressed {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n",
+				suppressed.Path,
+				suppressed.Category,
+				findingLocation(suppressed.Finding),
+				strings.ReplaceAll(suppressed.Rule, "|", "\\|"))
+		}
+		b.WriteString("\n</details>\n")
+	}
+
+	return b.String()
+}
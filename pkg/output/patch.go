@@ -0,0 +1,138 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/rs/zerolog/log"
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+)
+
+// jsonPatchOperation is a single RFC 6902 JSON Patch operation
+type jsonPatchOperation struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+}
+
+// RenderJSONPatch renders the removal set as an RFC 6902 JSON Patch
+func RenderJSONPatch(removed []string) ([]byte, error) {
+	operations := make([]jsonPatchOperation, 0, len(removed))
+	for _, path := range removed {
+		operations = append(operations, jsonPatchOperation{Op: "remove", Path: jsonPointer(path)})
+	}
+
+	return json.MarshalIndent(operations, "", "  ")
+}
+
+// RenderMergePatch renders the removal set as an RFC 7386 JSON Merge Patch, removed keys are set to null
+func RenderMergePatch(removed []string) ([]byte, error) {
+	patch := make(map[string]interface{})
+	for _, path := range removed {
+		parts := strings.Split(path, ".")
+		current := patch
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := current[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				current[part] = next
+			}
+			current = next
+		}
+		current[parts[len(parts)-1]] = nil
+	}
+
+	return json.MarshalIndent(patch, "", "  ")
+}
+
+// RenderUnifiedDiff renders the removal set as a unified diff against the original downstream file.
+// Only the lines holding removed keys and their values are deleted, everything else is kept verbatim.
+func RenderUnifiedDiff(original []byte, fileName string, removed []string) (string, error) {
+	_, positions, err := analyzer.ParseValues(original)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse downstream values: %w", err)
+	}
+
+	lines := splitLines(string(original))
+	deleted := make(map[int]bool)
+	for _, path := range removed {
+		start, end, ok := keyLineRange(lines, positions[path])
+		if !ok {
+			log.Warn().Msgf("Unable to locate %s in %s, it is not part of the diff", path, fileName)
+			continue
+		}
+		for i := start; i <= end; i++ {
+			deleted[i] = true
+		}
+	}
+
+	optimized := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if !deleted[i] {
+			optimized = append(optimized, line)
+		}
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        lines,
+		B:        optimized,
+		FromFile: "a/" + fileName,
+		ToFile:   "b/" + fileName,
+		Context:  3,
+	})
+}
+
+// splitLines splits content into lines keeping their line break. Unlike difflib.SplitLines, a trailing line break
+// does not add an empty line, which would not match the file when the diff is applied.
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	last := len(lines) - 1
+	if lines[last] == "" {
+		return lines[:last]
+	}
+	lines[last] += "\n"
+	return lines
+}
+
+// keyLineRange returns the zero-based range of lines, inclusive, occupied by a block-style key and its value
+func keyLineRange(lines []string, position analyzer.Position) (int, int, bool) {
+	start := position.Line - 1
+	indent := position.Column - 1
+	if start < 0 || start >= len(lines) || indent < 0 || indent > len(lines[start]) {
+		return 0, 0, false
+	}
+
+	// Keys sharing their line with other content (flow mappings, list items) cannot be removed by line
+	if strings.TrimSpace(lines[start][:indent]) != "" {
+		return 0, 0, false
+	}
+
+	end := start
+	for i := start + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" {
+			continue
+		}
+
+		lineIndent := len(lines[i]) - len(strings.TrimLeft(lines[i], " "))
+		isSequenceItem := lineIndent == indent && (trimmed == "-" || strings.HasPrefix(trimmed, "- "))
+		if lineIndent <= indent && !isSequenceItem {
+			break
+		}
+		end = i
+	}
+
+	return start, end, true
+}
+
+// jsonPointer converts a dot-notation path into an RFC 6901 JSON Pointer
+func jsonPointer(path string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(path, ".") {
+		part = strings.ReplaceAll(part, "~", "~0")
+		part = strings.ReplaceAll(part, "/", "~1")
+		b.WriteString("/" + part)
+	}
+	return b.String()
+}
@@ -0,0 +1,170 @@
+package output
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRenderJSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		removed []string
+		want    []jsonPatchOperation
+	}{
+		{
+			name:    "nothing removed",
+			removed: nil,
+			want:    []jsonPatchOperation{},
+		},
+		{
+			name:    "nested keys",
+			removed: []string{"image.tag", "replicas"},
+			want: []jsonPatchOperation{
+				{Op: "remove", Path: "/image/tag"},
+				{Op: "remove", Path: "/replicas"},
+			},
+		},
+		{
+			name:    "escaped keys",
+			removed: []string{"annotations.example~com/owner"},
+			want:    []jsonPatchOperation{{Op: "remove", Path: "/annotations/example~0com~1owner"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := RenderJSONPatch(tt.removed)
+			if err != nil {
+				t.Fatalf("RenderJSONPatch() error = %v", err)
+			}
+
+			var got []jsonPatchOperation
+			if err := json.Unmarshal(content, &got); err != nil {
+				t.Fatalf("invalid JSON Patch %s: %v", content, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RenderJSONPatch() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMergePatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		removed []string
+		want    map[string]interface{}
+	}{
+		{
+			name:    "nothing removed",
+			removed: nil,
+			want:    map[string]interface{}{},
+		},
+		{
+			name:    "top-level key",
+			removed: []string{"replicas"},
+			want:    map[string]interface{}{"replicas": nil},
+		},
+		{
+			name:    "sibling keys share their parent",
+			removed: []string{"image.tag", "image.pullPolicy", "service.port"},
+			want: map[string]interface{}{
+				"image":   map[string]interface{}{"tag": nil, "pullPolicy": nil},
+				"service": map[string]interface{}{"port": nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := RenderMergePatch(tt.removed)
+			if err != nil {
+				t.Fatalf("RenderMergePatch() error = %v", err)
+			}
+
+			var got map[string]interface{}
+			if err := json.Unmarshal(content, &got); err != nil {
+				t.Fatalf("invalid JSON Merge Patch %s: %v", content, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RenderMergePatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderUnifiedDiff(t *testing.T) {
+	const original = `# Image settings
+image:
+  tag: "1.0"
+  pullPolicy: Always
+replicas: 1
+tolerations:
+- key: dedicated
+  operator: Exists
+service: {port: 80}
+`
+
+	tests := []struct {
+		name    string
+		removed []string
+		want    string
+	}{
+		{
+			name:    "nothing removed",
+			removed: nil,
+			want:    "",
+		},
+		{
+			name:    "scalar value",
+			removed: []string{"image.tag"},
+			want: `--- a/values.yaml
++++ b/values.yaml
+@@ -1,6 +1,5 @@
+ # Image settings
+ image:
+-  tag: "1.0"
+   pullPolicy: Always
+ replicas: 1
+ tolerations:
+`,
+		},
+		{
+			name:    "block sequence",
+			removed: []string{"tolerations"},
+			want: `--- a/values.yaml
++++ b/values.yaml
+@@ -3,7 +3,4 @@
+   tag: "1.0"
+   pullPolicy: Always
+ replicas: 1
+-tolerations:
+-- key: dedicated
+-  operator: Exists
+ service: {port: 80}
+`,
+		},
+		{
+			name:    "flow mapping keys are skipped",
+			removed: []string{"service.port"},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderUnifiedDiff([]byte(original), "values.yaml", tt.removed)
+			if err != nil {
+				t.Fatalf("RenderUnifiedDiff() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RenderUnifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := RenderUnifiedDiff([]byte("image: ["), "values.yaml", nil); err == nil {
+		t.Errorf("RenderUnifiedDiff() of invalid values succeeded")
+	}
+}
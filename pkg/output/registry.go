@@ -0,0 +1,207 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+)
+
+// Writer produces one output format from the analysis results
+type Writer interface {
+	Write(m *Manager, valueStatus analyzer.ValueStatus) error
+}
+
+// WriterFunc adapts a function to the Writer interface
+type WriterFunc func(m *Manager, valueStatus analyzer.ValueStatus) error
+
+// Write calls f(m, valueStatus)
+func (f WriterFunc) Write(m *Manager, valueStatus analyzer.ValueStatus) error {
+	return f(m, valueStatus)
+}
+
+// StdoutWriter is implemented by writers that print their document to stdout instead of writing files.
+// Only one such writer can be selected per run, and logs are moved to stderr when one is.
+type StdoutWriter interface {
+	Writer
+	WritesStdout() bool
+}
+
+// stdoutWriterFunc is a WriterFunc that prints to stdout
+type stdoutWriterFunc WriterFunc
+
+func (f stdoutWriterFunc) Write(m *Manager, valueStatus analyzer.ValueStatus) error {
+	return f(m, valueStatus)
+}
+
+func (f stdoutWriterFunc) WritesStdout() bool {
+	return true
+}
+
+var (
+	writersMu sync.RWMutex
+	writers   = make(map[string]Writer)
+)
+
+// RegisterWriter makes a writer available under the given format name, replacing any writer of that name
+func RegisterWriter(name string, writer Writer) {
+	writersMu.Lock()
+	defer writersMu.Unlock()
+	writers[name] = writer
+}
+
+// LookupWriter returns the writer registered under the given format name
+func LookupWriter(name string) (Writer, bool) {
+	writersMu.RLock()
+	defer writersMu.RUnlock()
+	writer, ok := writers[name]
+	return writer, ok
+}
+
+// WriterNames returns the names of all registered writers, sorted
+func WriterNames() []string {
+	writersMu.RLock()
+	defer writersMu.RUnlock()
+
+	names := make([]string, 0, len(writers))
+	for name := range writers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseFormats splits a comma-separated list of format names
+func ParseFormats(format string) []string {
+	formats := []string{}
+	for _, name := range strings.Split(format, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			formats = append(formats, name)
+		}
+	}
+	return formats
+}
+
+// ValidateFormats checks that every format has a registered writer and at most one writes to stdout
+func ValidateFormats(formats []string) error {
+	var stdoutFormats []string
+	for _, format := range formats {
+		writer, ok := LookupWriter(format)
+		if !ok {
+			return fmt.Errorf("%w %q, available formats: %s", ErrUnknownFormat, format, strings.Join(WriterNames(), ", "))
+		}
+		if isStdoutWriter(writer) {
+			stdoutFormats = append(stdoutFormats, format)
+		}
+	}
+
+	if len(stdoutFormats) > 1 {
+		return fmt.Errorf("%w combination: %s all write to stdout, select only one of them", ErrUnknownFormat, strings.Join(stdoutFormats, ", "))
+	}
+
+	return nil
+}
+
+// UsesStdout reports whether any of the formats prints its document to stdout
+func UsesStdout(formats []string) bool {
+	for _, format := range formats {
+		if writer, ok := LookupWriter(format); ok && isStdoutWriter(writer) {
+			return true
+		}
+	}
+	return false
+}
+
+// WritesFiles reports whether any of the formats writes files to the output directory
+func WritesFiles(formats []string) bool {
+	for _, format := range formats {
+		if writer, ok := LookupWriter(format); ok && !isStdoutWriter(writer) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStdoutWriter reports whether a writer prints its document to stdout
+func isStdoutWriter(writer Writer) bool {
+	stdoutWriter, ok := writer.(StdoutWriter)
+	return ok && stdoutWriter.WritesStdout()
+}
+
+// Register the built-in writers
+func init() {
+	yamlFiles := WriterFunc(func(m *Manager, valueStatus analyzer.ValueStatus) error {
+		return m.writeYAMLFiles(valueStatus)
+	})
+	RegisterWriter("yaml-files", yamlFiles)
+	// Historical name of the default output
+	RegisterWriter("stdout", yamlFiles)
+
+	optimizedYAML := stdoutWriterFunc(func(m *Manager, valueStatus analyzer.ValueStatus) error {
+		return WriteOptimizedValues(os.Stdout, valueStatus)
+	})
+	RegisterWriter("optimized-yaml", optimizedYAML)
+	RegisterWriter("yaml", optimizedYAML)
+
+	RegisterWriter("json", stdoutWriterFunc(func(m *Manager, valueStatus analyzer.ValueStatus) error {
+		return WriteJSONReport(os.Stdout, valueStatus)
+	}))
+
+	RegisterWriter("sarif", stdoutWriterFunc(func(m *Manager, valueStatus analyzer.ValueStatus) error {
+		return WriteSARIFReport(os.Stdout, valueStatus)
+	}))
+
+	RegisterWriter("template", stdoutWriterFunc(func(m *Manager, valueStatus analyzer.ValueStatus) error {
+		return WriteTemplateReport(os.Stdout, m.TemplatePath, NewTemplateData(valueStatus, m.Metadata))
+	}))
+
+	markdown := WriterFunc(func(m *Manager, valueStatus analyzer.ValueStatus) error {
+		return m.writeReport(RenderMarkdownReport(valueStatus), m.Paths.MarkdownReportPath)
+	})
+	RegisterWriter("markdown", markdown)
+	RegisterWriter("md", markdown)
+
+	RegisterWriter("html", WriterFunc(func(m *Manager, valueStatus analyzer.ValueStatus) error {
+		report, err := RenderHTMLReport(valueStatus)
+		if err != nil {
+			return err
+		}
+		return m.writeReport(report, m.Paths.HTMLReportPath)
+	}))
+
+	RegisterWriter("junit", WriterFunc(func(m *Manager, valueStatus analyzer.ValueStatus) error {
+		var report strings.Builder
+		if err := WriteJUnitReport(&report, valueStatus, m.Policy); err != nil {
+			return err
+		}
+		return m.writeReport(report.String(), m.Paths.JUnitReportPath)
+	}))
+
+	RegisterWriter("json-patch", WriterFunc(func(m *Manager, valueStatus analyzer.ValueStatus) error {
+		patch, err := RenderJSONPatch(valueStatus.Removed)
+		if err != nil {
+			return fmt.Errorf("failed to render JSON patch: %w", err)
+		}
+		return m.writeReport(string(patch)+"\n", m.Paths.JSONPatchPath)
+	}))
+
+	RegisterWriter("merge-patch", WriterFunc(func(m *Manager, valueStatus analyzer.ValueStatus) error {
+		patch, err := RenderMergePatch(valueStatus.Removed)
+		if err != nil {
+			return fmt.Errorf("failed to render merge patch: %w", err)
+		}
+		return m.writeReport(string(patch)+"\n", m.Paths.MergePatchPath)
+	}))
+
+	RegisterWriter("diff", WriterFunc(func(m *Manager, valueStatus analyzer.ValueStatus) error {
+		diff, err := RenderUnifiedDiff(m.Original, filepath.ToSlash(valueStatus.Source), valueStatus.Removed)
+		if err != nil {
+			return fmt.Errorf("failed to render diff: %w", err)
+		}
+		return m.writeReport(diff, m.Paths.DiffPath)
+	}))
+}
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// marshalReport encodes a report in the requested format and returns the content with its file extension.
+// The markdown renderer is only invoked when a Markdown report is requested.
+func marshalReport(report interface{}, format string, markdown func() string) ([]byte, string, error) {
+	switch format {
+	case "json":
+		content, err := json.MarshalIndent(report, "", "  ")
+		return content, "json", err
+	case "markdown", "md":
+		return []byte(markdown()), "md", nil
+	case "yaml", "stdout", "":
+		content, err := yaml.Marshal(report)
+		return content, "yaml", err
+	default:
+		return nil, "", fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+// markdownValue renders a value as inline code suitable for a Markdown table cell
+func markdownValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	encoded, err := json.Marshal(jsonValue(value))
+	if err != nil {
+		encoded = []byte(fmt.Sprintf("%v", value))
+	}
+
+	return "`" + strings.ReplaceAll(string(encoded), "|", "\\|") + "`"
+}
+
+// jsonValue converts maps with non-string keys, as decoded by yaml.v2, into maps that encoding/json supports
+func jsonValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(typed))
+		for k, v := range typed {
+			converted[fmt.Sprintf("%v", k)] = jsonValue(v)
+		}
+		return converted
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(typed))
+		for k, v := range typed {
+			converted[k] = jsonValue(v)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(typed))
+		for i, v := range typed {
+			converted[i] = jsonValue(v)
+		}
+		return converted
+	default:
+		return value
+	}
+}
@@ -0,0 +1,228 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "helm-values-manager"
+	toolURI      = "https://github.com/xunholy/helm-values-manager"
+)
+
+// sarifRule describes how a finding category is reported in SARIF
+type sarifRule struct {
+	ID          string
+	Name        string
+	Description string
+	Level       string
+}
+
+// sarifRules maps each finding category to its SARIF rule
+var sarifRules = map[analyzer.Category]sarifRule{
+	analyzer.CategoryUnsupported: {
+		ID:          "HVM001",
+		Name:        "unsupported-key",
+		Description: "The key does not exist in the upstream chart values and is ignored by the chart.",
+		Level:       "warning",
+	},
+	analyzer.CategoryRedundant: {
+		ID:          "HVM002",
+		Name:        "redundant-value",
+		Description: "The value matches the upstream chart default and can be removed.",
+		Level:       "note",
+	},
+	analyzer.CategoryCommented: {
+		ID:          "HVM003",
+		Name:        "commented-key",
+		Description: "The key is only present as a commented-out example in the upstream chart values.",
+		Level:       "note",
+	},
+	analyzer.CategoryTypeMismatch: {
+		ID:          "HVM004",
+		Name:        "type-mismatch",
+		Description: "The value has a different type than the upstream chart default.",
+		Level:       "warning",
+	},
+	analyzer.CategorySchemaViolation: {
+		ID:          "HVM005",
+		Name:        "schema-violation",
+		Description: "The value is rejected by the values.schema.json of the chart, Helm refuses to install it.",
+		Level:       "error",
+	},
+}
+
+// sarifRuleOrder keeps the rule indexes stable
+var sarifRuleOrder = []analyzer.Category{
+	analyzer.CategoryUnsupported,
+	analyzer.CategoryRedundant,
+	analyzer.CategoryCommented,
+	analyzer.CategoryTypeMismatch,
+	analyzer.CategorySchemaViolation,
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string               `json:"name"`
+	InformationURI string               `json:"informationUri"`
+	Rules          []sarifReportingRule `json:"rules"`
+}
+
+type sarifReportingRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+	// PartialFingerprints let code scanning track a finding across runs, independently of its line
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIFReport writes the analysis results as a SARIF 2.1.0 log
+func WriteSARIFReport(w io.Writer, valueStatus analyzer.ValueStatus) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           toolName,
+				InformationURI: toolURI,
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	ruleIndexes := make(map[analyzer.Category]int, len(sarifRuleOrder))
+	for i, category := range sarifRuleOrder {
+		rule := sarifRules[category]
+		ruleIndexes[category] = i
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifReportingRule{
+			ID:                   rule.ID,
+			Name:                 rule.Name,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: rule.Level},
+		})
+	}
+
+	for _, finding := range valueStatus.Findings {
+		rule, known := sarifRules[finding.Category]
+		if !known {
+			continue
+		}
+
+		result := sarifResult{
+			RuleID:    rule.ID,
+			RuleIndex: ruleIndexes[finding.Category],
+			Level:     rule.Level,
+			Message:   sarifMessage{Text: sarifMessageText(finding)},
+			PartialFingerprints: map[string]string{
+				"helmValuesManager/v1": finding.Fingerprint(),
+			},
+		}
+
+		if finding.File != "" {
+			location := sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(finding.File)},
+			}
+			if finding.Line > 0 {
+				location.Region = &sarifRegion{StartLine: finding.Line, StartColumn: finding.Column}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: location}}
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	content, err := json.MarshalIndent(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal SARIF report: %w", err)
+	}
+
+	if _, err := fmt.Fprintln(w, string(content)); err != nil {
+		return fmt.Errorf("failed to write SARIF report: %w", err)
+	}
+
+	return nil
+}
+
+// sarifMessageText describes a finding in a single sentence
+func sarifMessageText(finding analyzer.Finding) string {
+	if finding.Anchor != "" {
+		return fmt.Sprintf("%s (set through the anchor defined at '%s')", sarifCategoryText(finding), finding.Anchor)
+	}
+	return sarifCategoryText(finding)
+}
+
+// sarifCategoryText describes a finding according to its category
+func sarifCategoryText(finding analyzer.Finding) string {
+	switch finding.Category {
+	case analyzer.CategoryUnsupported:
+		return fmt.Sprintf("'%s' is not a value of the upstream chart", finding.Path)
+	case analyzer.CategoryRedundant:
+		return fmt.Sprintf("'%s' matches the upstream default and can be removed", finding.Path)
+	case analyzer.CategoryCommented:
+		return fmt.Sprintf("'%s' is commented out in the upstream chart values", finding.Path)
+	case analyzer.CategoryTypeMismatch:
+		return fmt.Sprintf("'%s' is a %s but the upstream default is a %s",
+			finding.Path, analyzer.ValueKind(finding.Value), analyzer.ValueKind(finding.UpstreamValue))
+	case analyzer.CategorySchemaViolation:
+		return fmt.Sprintf("'%s' is rejected by the chart schema: %s", finding.Path, finding.Message)
+	default:
+		return finding.Path
+	}
+}
@@ -0,0 +1,109 @@
+package output
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+)
+
+func TestWriteSARIFReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		finding analyzer.Finding
+		want    sarifResult
+	}{
+		{
+			name:    "unsupported",
+			finding: analyzer.Finding{Path: "extra", Category: analyzer.CategoryUnsupported, File: "values.yaml", Line: 4, Column: 1},
+			want: sarifResult{
+				RuleID: "HVM001", RuleIndex: 0, Level: "warning",
+				Message:   sarifMessage{Text: "'extra' is not a value of the upstream chart"},
+				Locations: sarifLocations("values.yaml", &sarifRegion{StartLine: 4, StartColumn: 1}),
+			},
+		},
+		{
+			name:    "redundant through an anchor",
+			finding: analyzer.Finding{Path: "worker.cpu", Category: analyzer.CategoryRedundant, File: "values.yaml", Line: 9, Column: 8, Anchor: "resources.cpu"},
+			want: sarifResult{
+				RuleID: "HVM002", RuleIndex: 1, Level: "note",
+				Message:   sarifMessage{Text: "'worker.cpu' matches the upstream default and can be removed (set through the anchor defined at 'resources.cpu')"},
+				Locations: sarifLocations("values.yaml", &sarifRegion{StartLine: 9, StartColumn: 8}),
+			},
+		},
+		{
+			name:    "commented without a line",
+			finding: analyzer.Finding{Path: "service.type", Category: analyzer.CategoryCommented, File: "env/prod/values.yaml"},
+			want: sarifResult{
+				RuleID: "HVM003", RuleIndex: 2, Level: "note",
+				Message:   sarifMessage{Text: "'service.type' is commented out in the upstream chart values"},
+				Locations: sarifLocations("env/prod/values.yaml", nil),
+			},
+		},
+		{
+			name:    "type mismatch without a file",
+			finding: analyzer.Finding{Path: "replicas", Category: analyzer.CategoryTypeMismatch, Value: true, UpstreamValue: 1},
+			want: sarifResult{
+				RuleID: "HVM004", RuleIndex: 3, Level: "warning",
+				Message: sarifMessage{Text: "'replicas' is a bool but the upstream default is a number"},
+			},
+		},
+		{
+			name: "schema violation",
+			finding: analyzer.Finding{Path: "service.type", Category: analyzer.CategorySchemaViolation, File: "values.yaml", Line: 6,
+				Message: `service.type must be one of the following: "ClusterIP"`},
+			want: sarifResult{
+				RuleID: "HVM005", RuleIndex: 4, Level: "error",
+				Message:   sarifMessage{Text: `'service.type' is rejected by the chart schema: service.type must be one of the following: "ClusterIP"`},
+				Locations: sarifLocations("values.yaml", &sarifRegion{StartLine: 6}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := WriteSARIFReport(&b, analyzer.ValueStatus{Findings: []analyzer.Finding{tt.finding}}); err != nil {
+				t.Fatalf("WriteSARIFReport() error = %v", err)
+			}
+
+			var report sarifLog
+			if err := json.Unmarshal([]byte(b.String()), &report); err != nil {
+				t.Fatalf("invalid SARIF log: %v", err)
+			}
+			if report.Version != sarifVersion || len(report.Runs) != 1 {
+				t.Fatalf("log = version %s with %d runs, want a single %s run", report.Version, len(report.Runs), sarifVersion)
+			}
+
+			run := report.Runs[0]
+			if len(run.Tool.Driver.Rules) != len(sarifRuleOrder) {
+				t.Errorf("rules = %d, want %d", len(run.Tool.Driver.Rules), len(sarifRuleOrder))
+			}
+			if len(run.Results) != 1 {
+				t.Fatalf("results = %d, want 1", len(run.Results))
+			}
+
+			got := run.Results[0]
+			if rule := run.Tool.Driver.Rules[got.RuleIndex]; rule.ID != got.RuleID {
+				t.Errorf("rule index %d points to %s, want %s", got.RuleIndex, rule.ID, got.RuleID)
+			}
+			if got.PartialFingerprints["helmValuesManager/v1"] != tt.finding.Fingerprint() {
+				t.Errorf("partial fingerprints = %v, want the finding fingerprint", got.PartialFingerprints)
+			}
+			got.PartialFingerprints = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("result = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// sarifLocations returns the locations of a result in a single file
+func sarifLocations(uri string, region *sarifRegion) []sarifLocation {
+	return []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: uri},
+		Region:           region,
+	}}}
+}
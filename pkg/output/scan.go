@@ -0,0 +1,55 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xunholy/helm-values-manager/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// ScanResult summarizes the analysis of a single release during a scan
+type ScanResult struct {
+	Namespace   string `yaml:"namespace"`
+	Release     string `yaml:"release"`
+	Chart       string `yaml:"chart,omitempty"`
+	Redundant   int    `yaml:"redundant"`
+	Unsupported int    `yaml:"unsupported"`
+	Commented   int    `yaml:"commented"`
+	OutputDir   string `yaml:"outputDir,omitempty"`
+	Error       string `yaml:"error,omitempty"`
+}
+
+// WriteScanSummary prints a summary table of the scanned releases and saves it to the output directory
+func WriteScanSummary(results []ScanResult, outputDir string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tRELEASE\tCHART\tREDUNDANT\tUNSUPPORTED\tCOMMENTED\tSTATUS")
+	for _, result := range results {
+		status := "ok"
+		if result.Error != "" {
+			status = "error: " + result.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%s\n",
+			result.Namespace, result.Release, result.Chart,
+			result.Redundant, result.Unsupported, result.Commented, status)
+	}
+	if err := w.Flush(); err != nil {
+		return wrapError("scan summary", err)
+	}
+
+	summary, err := yaml.Marshal(map[string]interface{}{"releases": results})
+	if err != nil {
+		return wrapError("scan summary", err)
+	}
+
+	summaryFilePath := filepath.Join(outputDir, "scan-summary.yaml")
+	if err := util.CreateOutputFile(summary, summaryFilePath); err != nil {
+		return wrapError("scan summary", err)
+	}
+
+	log.Info().Msgf("Scan summary written to: %s", summaryFilePath)
+	return nil
+}
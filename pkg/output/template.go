@@ -0,0 +1,113 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"gopkg.in/yaml.v2"
+)
+
+// Metadata describes the inputs of an analysis
+type Metadata struct {
+	// Chart is the name of the upstream chart, if the values were fetched from a chart
+	Chart string
+	// ChartVersion is the resolved version of the upstream chart, empty when the values do not come from a chart
+	ChartVersion string
+	// Release is the name of the Helm release the upstream values were fetched from
+	Release string
+	// Upstream is the path of the upstream values file, if one was given
+	Upstream string
+	// Downstream is the path of the downstream values file
+	Downstream string
+}
+
+// TemplateData is the model user-defined report templates are executed against
+type TemplateData struct {
+	Metadata
+	// Summary holds the number of findings per category
+	Summary analyzer.Summary
+	// Findings lists every finding sorted by path
+	Findings []analyzer.Finding
+	// Redundant, Unsupported and Commented hold the flagged values as nested maps
+	Redundant   map[string]interface{}
+	Unsupported map[string]interface{}
+	Commented   map[string]interface{}
+	// Optimized holds the downstream values without the removed entries
+	Optimized map[string]interface{}
+	// Removed lists the paths removed from the optimized values
+	Removed []string
+	// Suppressed lists the findings hidden by ignore rules, with the rule that matched
+	Suppressed []analyzer.SuppressedFinding
+}
+
+// templateFuncs are the helper functions available to report templates
+var templateFuncs = template.FuncMap{
+	"toYaml": func(value interface{}) string {
+		encoded, err := yaml.Marshal(value)
+		if err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+		return strings.TrimSuffix(string(encoded), "\n")
+	},
+	"toJson": func(value interface{}) string {
+		encoded, err := json.Marshal(jsonValue(value))
+		if err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+		return string(encoded)
+	},
+	"csv": func(fields ...interface{}) string {
+		var b strings.Builder
+		record := make([]string, 0, len(fields))
+		for _, field := range fields {
+			record = append(record, fmt.Sprintf("%v", field))
+		}
+		w := csv.NewWriter(&b)
+		_ = w.Write(record)
+		w.Flush()
+		return strings.TrimSuffix(b.String(), "\n")
+	},
+	"join":     strings.Join,
+	"location": findingLocation,
+}
+
+// NewTemplateData builds the template model from the analysis results
+func NewTemplateData(valueStatus analyzer.ValueStatus, metadata Metadata) TemplateData {
+	return TemplateData{
+		Metadata:    metadata,
+		Summary:     valueStatus.Summarize(),
+		Findings:    valueStatus.Findings,
+		Redundant:   valueStatus.Redundant,
+		Unsupported: valueStatus.Unsupported,
+		Commented:   valueStatus.Commented,
+		Optimized:   valueStatus.Optimized,
+		Removed:     valueStatus.Removed,
+		Suppressed:  valueStatus.Suppressed,
+	}
+}
+
+// WriteTemplateReport executes a user-defined text/template file against the analysis results
+func WriteTemplateReport(w io.Writer, templatePath string, data TemplateData) error {
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return fmt.Errorf("%w: failed to read: %v", ErrInvalidTemplate, err)
+	}
+
+	tmpl, err := template.New(filepath.Base(templatePath)).Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("%w: failed to execute: %v", ErrInvalidTemplate, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xunholy/helm-values-manager/pkg/analyzer"
+	"gopkg.in/yaml.v2"
+)
+
+// WriteUpgrade prints the impact of a chart upgrade on the downstream values as text, or encoded as json or yaml
+func WriteUpgrade(w io.Writer, changes []analyzer.UpgradeChange, currentName, targetName, format string) error {
+	switch format {
+	case "json":
+		converted := make([]analyzer.UpgradeChange, len(changes))
+		for i, change := range changes {
+			change.Downstream = jsonValue(change.Downstream)
+			change.CurrentDefault = jsonValue(change.CurrentDefault)
+			change.TargetDefault = jsonValue(change.TargetDefault)
+			converted[i] = change
+		}
+		content, err := json.MarshalIndent(converted, "", "  ")
+		if err != nil {
+			return wrapError("upgrade", err)
+		}
+		_, err = fmt.Fprintln(w, string(content))
+		return wrapError("upgrade", err)
+	case "yaml":
+		content, err := yaml.Marshal(changes)
+		if err != nil {
+			return wrapError("upgrade", err)
+		}
+		_, err = w.Write(content)
+		return wrapError("upgrade", err)
+	case "text", "":
+		_, err := io.WriteString(w, upgradeText(changes, currentName, targetName))
+		return wrapError("upgrade", err)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+// upgradeText renders the impact of an upgrade for a terminal, one line per changed key
+func upgradeText(changes []analyzer.UpgradeChange, currentName, targetName string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Upgrading from %s to %s\n", currentName, targetName)
+	if len(changes) == 0 {
+		b.WriteString("\nNo changed default affects the downstream values\n")
+		return b.String()
+	}
+
+	b.WriteString("\n")
+	for _, change := range changes {
+		fmt.Fprintf(&b, "%-16s %s: %s\n", change.Impact, change.Path, upgradeMessage(change))
+	}
+
+	return b.String()
+}
+
+// upgradeMessage explains the impact of a single change
+func upgradeMessage(change analyzer.UpgradeChange) string {
+	switch change.Impact {
+	case analyzer.ImpactRemoved:
+		return "set downstream but no longer defined by the chart"
+	case analyzer.ImpactTypeChanged:
+		return fmt.Sprintf("set to a %s but the default becomes a %s", analyzer.ValueKind(change.Downstream), analyzer.ValueKind(change.TargetDefault))
+	case analyzer.ImpactPinned:
+		return fmt.Sprintf("keeps %s while the default becomes %s", inlineYAML(change.Downstream), inlineYAML(change.TargetDefault))
+	case analyzer.ImpactRedundant:
+		return fmt.Sprintf("set to %s, the new default, and can be removed", inlineYAML(change.Downstream))
+	case analyzer.ImpactDefaultChanged:
+		return fmt.Sprintf("default changes from %s to %s", inlineYAML(change.CurrentDefault), inlineYAML(change.TargetDefault))
+	default:
+		return change.Impact
+	}
+}
+
+// inlineYAML renders a value on a single line, as JSON which is also YAML flow style
+func inlineYAML(value interface{}) string {
+	content, err := json.Marshal(jsonValue(value))
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(content)
+}
@@ -2,7 +2,8 @@ package output
 
 import (
 	"fmt"
-	"path/filepath"
+	"io"
+	"sort"
 
 	"github.com/rs/zerolog/log"
 	"github.com/xunholy/helm-values-manager/pkg/analyzer"
@@ -12,28 +13,65 @@ import (
 
 // Manager handles the writing of output files
 type Manager struct {
-	Paths    analyzer.PathOptions
-	Format   string
+	Paths analyzer.PathOptions
+	// Formats are the names of the registered writers producing the output
+	Formats  []string
 	Optimize bool
+	Policy   analyzer.Policy
+	// Original is the content of the downstream values file, used to render text diffs
+	Original []byte
+	// TemplatePath is the text/template file used by the template format
+	TemplatePath string
+	Metadata     Metadata
 }
 
-// NewManager creates a new output manager
+// NewManager creates a new output manager for a comma-separated list of formats
 func NewManager(paths analyzer.PathOptions, format string, optimize bool) *Manager {
 	return &Manager{
 		Paths:    paths,
-		Format:   format,
+		Formats:  ParseFormats(format),
 		Optimize: optimize,
 	}
 }
 
-// WriteResults writes the analysis results to files and stdout
+// WriteResults writes the analysis results with every selected writer
 func (m *Manager) WriteResults(valueStatus analyzer.ValueStatus) error {
+	if err := ValidateFormats(m.Formats); err != nil {
+		return wrapError("select", err)
+	}
+
+	for _, format := range m.Formats {
+		writer, _ := LookupWriter(format)
+		if err := writer.Write(m, valueStatus); err != nil {
+			return wrapError(format, err)
+		}
+	}
+
+	return nil
+}
+
+// WriteOptimizedValues writes only the optimized values as YAML, for use in pipelines
+func WriteOptimizedValues(w io.Writer, valueStatus analyzer.ValueStatus) error {
+	optimizedValues, err := marshalOptimized(valueStatus)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(optimizedValues); err != nil {
+		return fmt.Errorf("failed to write optimized values: %w", err)
+	}
+
+	return nil
+}
+
+// writeYAMLFiles writes the optimized values and the per-category analysis files
+func (m *Manager) writeYAMLFiles(valueStatus analyzer.ValueStatus) error {
 	// Always generate the optimized values file (for backward compatibility with tests)
 	// even if optimize flag is not set
 	log.Info().Msg("Generating optimized values.yaml")
-	optimizedValues, err := yaml.Marshal(valueStatus.Optimized)
+	optimizedValues, err := marshalOptimized(valueStatus)
 	if err != nil {
-		return fmt.Errorf("failed to marshal optimized values: %w", err)
+		return err
 	}
 
 	// Save to file
@@ -93,7 +131,7 @@ func (m *Manager) writeAnalysisFiles(valueStatus analyzer.ValueStatus) error {
 	// Process unsupported values
 	unsupportedCount := analyzer.CountNestedKeys(valueStatus.Unsupported)
 	if unsupportedCount > 0 {
-		log.Info().Msgf("Found %d unsupported values", unsupportedCount)
+		log.Info().Msgf("Found %s", util.Count(unsupportedCount, "unsupported value"))
 
 		// Save to file
 		unsupportedValues, err := yaml.Marshal(valueStatus.Unsupported)
@@ -119,7 +157,7 @@ func (m *Manager) writeAnalysisFiles(valueStatus analyzer.ValueStatus) error {
 	// Process commented values (values that exist in upstream but are commented out)
 	commentedCount := analyzer.CountNestedKeys(valueStatus.Commented)
 	if commentedCount > 0 {
-		log.Info().Msgf("Found %d values that are commented out in the upstream chart", commentedCount)
+		log.Info().Msgf("Found %s commented out in the upstream chart", util.Count(commentedCount, "value"))
 
 		// Save to file
 		commentedValues, err := yaml.Marshal(valueStatus.Commented)
@@ -127,7 +165,7 @@ func (m *Manager) writeAnalysisFiles(valueStatus analyzer.ValueStatus) error {
 			return fmt.Errorf("failed to marshal commented values: %w", err)
 		}
 
-		commentedFilePath := filepath.Join(m.Paths.OutputDir, "commented-values.yaml")
+		commentedFilePath := m.Paths.CommentedValuesPath
 		if err := util.CreateOutputFile(commentedValues, commentedFilePath); err != nil {
 			return fmt.Errorf("failed to write commented values: %w", err)
 		}
@@ -144,7 +182,7 @@ func (m *Manager) writeAnalysisFiles(valueStatus analyzer.ValueStatus) error {
 	// Process redundant values
 	redundantCount := analyzer.CountNestedKeys(valueStatus.Redundant)
 	if redundantCount > 0 {
-		log.Info().Msgf("Found %d redundant values", redundantCount)
+		log.Info().Msgf("Found %s", util.Count(redundantCount, "redundant value"))
 
 		// Save to file
 		redundantValues, err := yaml.Marshal(valueStatus.Redundant)
@@ -162,5 +200,78 @@ func (m *Manager) writeAnalysisFiles(valueStatus analyzer.ValueStatus) error {
 		log.Info().Msg("No redundant values found")
 	}
 
+	// Process the flat list of findings with their source positions
+	if len(valueStatus.Findings) > 0 || len(valueStatus.Suppressed) > 0 {
+		document := map[string]interface{}{"findings": valueStatus.Findings}
+		if len(valueStatus.Suppressed) > 0 {
+			document["suppressed"] = valueStatus.Suppressed
+		}
+
+		findings, err := yaml.Marshal(document)
+		if err != nil {
+			return fmt.Errorf("failed to marshal findings: %w", err)
+		}
+
+		findingsFilePath := m.Paths.FindingsPath
+		if err := util.CreateOutputFile(findings, findingsFilePath); err != nil {
+			return fmt.Errorf("failed to write findings: %w", err)
+		}
+
+		log.Info().Msgf("Findings with source positions written to: %s", findingsFilePath)
+	}
+
+	// Report what the ignore rules hid, so deliberate values stay visible
+	if len(valueStatus.Suppressed) > 0 {
+		log.Info().Msgf("Suppressed %s with ignore rules", util.Count(len(valueStatus.Suppressed), "finding"))
+		counts := valueStatus.SuppressedByRule()
+		rules := make([]string, 0, len(counts))
+		for rule := range counts {
+			rules = append(rules, rule)
+		}
+		sort.Strings(rules)
+		for _, rule := range rules {
+			log.Info().Msgf("  %d suppressed by %s", counts[rule], rule)
+		}
+	}
+
 	return nil
 }
+
+// writeReport writes a rendered human-readable report to the given path
+func (m *Manager) writeReport(report, reportFilePath string) error {
+	if err := util.CreateOutputFile([]byte(report), reportFilePath); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	log.Info().Msgf("Report written to: %s", reportFilePath)
+	return nil
+}
+
+// WriteBaseline records the findings of an analysis as a baseline file
+func WriteBaseline(valueStatus analyzer.ValueStatus, baselinePath string) error {
+	baseline := analyzer.NewBaseline(valueStatus)
+	content, err := yaml.Marshal(baseline)
+	if err != nil {
+		return fmt.Errorf("failed to marshal baseline: %w", err)
+	}
+
+	if err := util.CreateOutputFile(content, baselinePath); err != nil {
+		return wrapError("baseline", err)
+	}
+
+	log.Info().Msgf("Baseline with %s written to: %s", util.Count(len(baseline.Findings), "finding"), baselinePath)
+	return nil
+}
+
+// marshalOptimized encodes the optimized values from the downstream file, keeping its comments and anchors
+func marshalOptimized(valueStatus analyzer.ValueStatus) ([]byte, error) {
+	if len(valueStatus.OptimizedDocument) > 0 {
+		return valueStatus.OptimizedDocument, nil
+	}
+
+	optimizedValues, err := yaml.Marshal(valueStatus.Optimized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal optimized values: %w", err)
+	}
+	return optimizedValues, nil
+}
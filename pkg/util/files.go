@@ -2,8 +2,10 @@ package util
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/rs/zerolog/log"
@@ -46,3 +48,58 @@ func FindKubeConfig() (string, error) {
 func EnsureDirectory(dir string) error {
 	return os.MkdirAll(dir, 0755)
 }
+
+// StdinPath is the path that selects standard input instead of a file
+const StdinPath = "-"
+
+// ReadInput reads the content of a file, or of standard input when the path is "-"
+func ReadInput(path string) ([]byte, error) {
+	if path == StdinPath {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// DisplayName returns the name used to refer to an input in logs and reports
+func DisplayName(path string) string {
+	if path == StdinPath {
+		return "<stdin>"
+	}
+	return path
+}
+
+// FindRepoRoot returns the closest directory holding a .git entry, starting from dir, or an empty string
+func FindRepoRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// RelativeName returns the name of an input relative to root when it is inside it, its display name otherwise
+func RelativeName(path, root string) string {
+	if path == StdinPath || root == "" {
+		return DisplayName(path)
+	}
+
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	relative, err := filepath.Rel(root, absolute)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return relative
+}
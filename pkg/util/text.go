@@ -0,0 +1,11 @@
+package util
+
+import "fmt"
+
+// Count formats a number followed by a noun, in its plural form unless the number is one (e.g. 1 value, 2 values)
+func Count(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
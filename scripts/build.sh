@@ -23,7 +23,7 @@ mkdir -p bin
 # Check if we're using the new module structure
 if [ -d "cmd/helm-values-manager" ]; then
     echo -e "${YELLOW}Using new module structure${RESET}"
-    go build -ldflags "-X main.version=${VERSION}" -o bin/helm-values-manager cmd/helm-values-manager/main.go
+    go build -ldflags "-X main.version=${VERSION}" -o bin/helm-values-manager ./cmd/helm-values-manager
 
     if [ $? -eq 0 ]; then
         echo -e "${GREEN}Build successful!${RESET}"
@@ -344,7 +344,7 @@ if [ -f "go.mod" ] && [ -f "Taskfile.yml" ]; then
   if command -v task &> /dev/null; then
     task build
   elif [ -d "cmd/helm-values-manager" ]; then
-    go build -o "${valueManagerBin}" cmd/helm-values-manager/main.go
+    go build -o "${valueManagerBin}" ./cmd/helm-values-manager
   else
     go build -o "${valueManagerBin}" main.go
   fi
@@ -8,7 +8,7 @@ RED='\033[0;31m'
 NC='\033[0m' # No Color
 
 echo -e "${BLUE}Building Helm Values Manager...${NC}"
-go build -o bin/value-manager main.go
+go build -o bin/value-manager ./cmd/helm-values-manager
 
 echo -e "${BLUE}Running test with controlled test case...${NC}"
 
@@ -26,7 +26,7 @@ service:
 EOF
 
 # Run test with optimization
-bin/value-manager -upstream examples/test-upstream.yaml -downstream examples/test-downstream.yaml -optimize -output yaml
+bin/value-manager -upstream examples/test-upstream.yaml -downstream examples/test-downstream.yaml -optimize -output yaml-files -outdir examples
 
 # Since our service section handling is separate, merge the additional unsupported values
 # This is just for testing purposes